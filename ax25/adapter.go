@@ -0,0 +1,174 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package ax25
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	satcom "github.com/antaris-inc/go-satcom"
+	"github.com/antaris-inc/go-satcom/hdlc"
+)
+
+type UIAdapterConfig struct {
+	// Addresses used for all frames sent
+	Destination Address
+	Source      Address
+	Digipeaters []Address
+
+	// Protocol identifier used for all frames sent, defaulting to
+	// PID_NO_LAYER_3.
+	PID byte
+
+	// Append and verify the FCS of each frame. This is not needed
+	// when frames are carried by HDLC, which handles the FCS itself,
+	// or by KISS, where it is handled by the TNC.
+	FCS bool
+
+	// Only accept frames addressed to Destination when unwrapping.
+	// Otherwise, UI frames from any station are accepted.
+	FilterDestination bool
+}
+
+func (cfg *UIAdapterConfig) Err() error {
+	if err := cfg.Destination.Err(); err != nil {
+		return fmt.Errorf("Destination: %v", err)
+	}
+	if err := cfg.Source.Err(); err != nil {
+		return fmt.Errorf("Source: %v", err)
+	}
+	if len(cfg.Digipeaters) > MAX_DIGIPEATERS {
+		return errors.New("too many Digipeaters")
+	}
+	for i, d := range cfg.Digipeaters {
+		if err := d.Err(); err != nil {
+			return fmt.Errorf("Digipeater %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func NewUIAdapter(cfg UIAdapterConfig) (*UIAdapter, error) {
+	if err := cfg.Err(); err != nil {
+		return nil, err
+	}
+	if cfg.PID == 0 {
+		cfg.PID = PID_NO_LAYER_3
+	}
+	a := UIAdapter{cfg: cfg}
+	for _, d := range cfg.Digipeaters {
+		a.digis = append(a.digis, Digipeater{Address: d})
+	}
+	return &a, nil
+}
+
+// Wraps messages in AX.25 UI frames. Implements the satcom.Adapter
+// interface, along with satcom.AppendAdapter and satcom.AnnotatingAdapter.
+// Unwrapping accepts UI frames only, reporting the source and destination
+// of each as the "ax25.source" and "ax25.destination" annotations.
+type UIAdapter struct {
+	cfg   UIAdapterConfig
+	digis []Digipeater
+}
+
+// Returns the frame used to wrap the provided message.
+func (a *UIAdapter) frame(msg []byte) Frame {
+	return Frame{
+		Destination: a.cfg.Destination,
+		Source:      a.cfg.Source,
+		Digipeaters: a.digis,
+		Command:     true,
+		Control:     UControl(U_TYPE_UI, false),
+		PID:         a.cfg.PID,
+		Info:        msg,
+	}
+}
+
+func (a *UIAdapter) MessageSize(n int) (int, error) {
+	f := a.frame(nil)
+	n += f.Size()
+	if a.cfg.FCS {
+		n += hdlc.FCS_LENGTH_BYTES
+	}
+	return n, nil
+}
+
+func (a *UIAdapter) Wrap(msg []byte) ([]byte, error) {
+	n, _ := a.MessageSize(len(msg))
+	return a.AppendWrap(make([]byte, 0, n), msg)
+}
+
+// Append the UI frame wrapping the provided message to dst.
+func (a *UIAdapter) AppendWrap(dst, msg []byte) ([]byte, error) {
+	start := len(dst)
+
+	f := a.frame(msg)
+	dst, err := AppendEncode(dst, &f)
+	if err != nil {
+		return dst, err
+	}
+
+	if a.cfg.FCS {
+		dst = binary.LittleEndian.AppendUint16(dst, hdlc.FCS(dst[start:]))
+	}
+	return dst, nil
+}
+
+func (a *UIAdapter) Unwrap(frm []byte) ([]byte, error) {
+	msg, _, err := a.unwrap(frm)
+	return msg, err
+}
+
+// Decode the provided frame, returning a subslice holding its Info field.
+func (a *UIAdapter) UnwrapInPlace(frm []byte) ([]byte, error) {
+	return a.Unwrap(frm)
+}
+
+func (a *UIAdapter) UnwrapAnnotated(frm []byte) ([]byte, []satcom.Annotation, error) {
+	msg, f, err := a.unwrap(frm)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	anns := []satcom.Annotation{
+		{Name: "ax25.source", Value: f.Source},
+		{Name: "ax25.destination", Value: f.Destination},
+	}
+	return msg, anns, nil
+}
+
+func (a *UIAdapter) unwrap(frm []byte) ([]byte, *Frame, error) {
+	if a.cfg.FCS {
+		var err error
+		if frm, err = hdlc.CheckFCS(frm); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	f, err := Decode(frm)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if f.Type() != FRAME_TYPE_U || f.UType() != U_TYPE_UI {
+		return nil, nil, errors.New("not a UI frame")
+	}
+	if a.cfg.FilterDestination && f.Destination != a.cfg.Destination {
+		return nil, nil, fmt.Errorf("frame addressed to %v", f.Destination)
+	}
+
+	return f.Info, &f, nil
+}
@@ -0,0 +1,209 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package ax25
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	satcom "github.com/antaris-inc/go-satcom"
+	"github.com/antaris-inc/go-satcom/hdlc"
+)
+
+func TestUIAdapter(t *testing.T) {
+	tests := []struct {
+		cfg  UIAdapterConfig
+		msg  []byte
+		want []byte
+	}{
+		{
+			cfg: UIAdapterConfig{
+				Destination: testDest,
+				Source:      testSrc,
+			},
+			msg: []byte("hi"),
+			want: []byte{
+				0x82, 0xA0, 0xA4, 0xA6, 0x40, 0x40, 0xE0,
+				0x9C, 0x60, 0x86, 0x82, 0x98, 0x98, 0x6F,
+				0x03, 0xF0, 'h', 'i',
+			},
+		},
+		{
+			cfg: UIAdapterConfig{
+				Destination: testDest,
+				Source:      testSrc,
+				Digipeaters: []Address{{Callsign: "WIDE1", SSID: 1}},
+				PID:         PID_IP,
+				FCS:         true,
+			},
+			msg: []byte{0x45},
+			want: hdlc.AppendFCS([]byte{
+				0x82, 0xA0, 0xA4, 0xA6, 0x40, 0x40, 0xE0,
+				0x9C, 0x60, 0x86, 0x82, 0x98, 0x98, 0x6E,
+				0xAE, 0x92, 0x88, 0x8A, 0x62, 0x40, 0x63,
+				0x03, 0xCC, 0x45,
+			}),
+		},
+	}
+
+	for i, tt := range tests {
+		ad, err := NewUIAdapter(tt.cfg)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+
+		got, err := ad.Wrap(tt.msg)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: unexpected result: want=% x got=% x", i, tt.want, got)
+			continue
+		}
+		if n, _ := ad.MessageSize(len(tt.msg)); n != len(got) {
+			t.Errorf("case %d: unexpected message size: want=%d got=%d", i, len(got), n)
+		}
+
+		msg, anns, err := ad.UnwrapAnnotated(got)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.msg, msg) {
+			t.Errorf("case %d: unexpected message: want=% x got=% x", i, tt.msg, msg)
+		}
+		wantAnns := []satcom.Annotation{
+			{Name: "ax25.source", Value: testSrc},
+			{Name: "ax25.destination", Value: testDest},
+		}
+		if !reflect.DeepEqual(wantAnns, anns) {
+			t.Errorf("case %d: unexpected annotations: %+v", i, anns)
+		}
+	}
+}
+
+func TestUIAdapter_Unwrap_Invalid(t *testing.T) {
+	ad, err := NewUIAdapter(UIAdapterConfig{
+		Destination:       testDest,
+		Source:            testSrc,
+		FCS:               true,
+		FilterDestination: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	other, err := AppendEncode(nil, &Frame{
+		Destination: Address{Callsign: "CQ"},
+		Source:      testSrc,
+		Control:     UControl(U_TYPE_UI, false),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sabm, err := AppendEncode(nil, &Frame{
+		Destination: testDest,
+		Source:      testSrc,
+		Control:     UControl(U_TYPE_SABM, true),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	good, err := ad.Wrap([]byte("hi"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bad := append([]byte{}, good...)
+	bad[len(bad)-3] ^= 0x01
+
+	tests := [][]byte{
+		{},
+		bad,
+		hdlc.AppendFCS(other),
+		hdlc.AppendFCS(sabm),
+	}
+
+	for i, tt := range tests {
+		if _, err := ad.Unwrap(tt); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestNewUIAdapter_Invalid(t *testing.T) {
+	tests := []UIAdapterConfig{
+		{Source: testSrc},
+		{Destination: testDest},
+		{Destination: testDest, Source: testSrc, Digipeaters: []Address{{Callsign: "WIDE1-1"}}},
+	}
+
+	for i, tt := range tests {
+		if _, err := NewUIAdapter(tt); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestUIAdapter_FrameSenderReceiver(t *testing.T) {
+	codec, err := hdlc.NewCodec(hdlc.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ad, err := NewUIAdapter(UIAdapterConfig{
+		Destination: testDest,
+		Source:      testSrc,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	frameSize, _ := ad.MessageSize(64)
+	cfg := satcom.FrameConfig{
+		Codec:     codec,
+		FrameSize: frameSize,
+		Adapters:  []satcom.Adapter{ad},
+	}
+
+	var buf bytes.Buffer
+	fs, err := satcom.NewFrameSender(cfg, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg := []byte(">hello from orbit")
+	if err := fs.Send(msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fr, err := satcom.NewFrameReceiver(cfg, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	frm, err := fr.Next(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(msg, frm.Payload) {
+		t.Errorf("unexpected result: want=% x got=% x", msg, frm.Payload)
+	}
+	if len(frm.Annotations) != 2 || frm.Annotations[0].Value != testSrc {
+		t.Errorf("unexpected annotations: %+v", frm.Annotations)
+	}
+}
@@ -0,0 +1,137 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package ax25 encodes and decodes AX.25 link-layer frames, as used on
+// amateur radio links. Frames may be carried using HDLC (see the hdlc
+// package) or KISS (see the kiss package), or used as an Adapter with
+// sync marker framing. Only modulo 8 sequence numbering is supported.
+// See https://www.tapr.org/pdf/AX25.2.2.pdf
+package ax25
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	ADDRESS_LENGTH_BYTES = 7
+	MAX_CALLSIGN_LENGTH  = 6
+	MAX_SSID             = 15
+	MAX_DIGIPEATERS      = 8
+)
+
+// A station address, made up of a callsign and secondary station
+// identifier (SSID).
+type Address struct {
+	Callsign string
+	SSID     uint8
+}
+
+// Parse an address of the form "CALL" or "CALL-SSID".
+func ParseAddress(s string) (Address, error) {
+	var a Address
+
+	call, ssid, found := strings.Cut(s, "-")
+	a.Callsign = strings.ToUpper(call)
+	if found {
+		n, err := strconv.ParseUint(ssid, 10, 8)
+		if err != nil {
+			return Address{}, fmt.Errorf("invalid SSID %q", ssid)
+		}
+		a.SSID = uint8(n)
+	}
+
+	if err := a.Err(); err != nil {
+		return Address{}, err
+	}
+	return a, nil
+}
+
+func (a Address) Err() error {
+	if len(a.Callsign) == 0 || len(a.Callsign) > MAX_CALLSIGN_LENGTH {
+		return errors.New("Callsign must be 1-6 characters")
+	}
+	for _, c := range a.Callsign {
+		if !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+			return fmt.Errorf("Callsign contains invalid character %q", c)
+		}
+	}
+	if a.SSID > MAX_SSID {
+		return errors.New("SSID must not exceed 15")
+	}
+	return nil
+}
+
+// Returns the address in the form "CALL-SSID", omitting a zero SSID.
+func (a Address) String() string {
+	if a.SSID == 0 {
+		return a.Callsign
+	}
+	return a.Callsign + "-" + strconv.Itoa(int(a.SSID))
+}
+
+// Append the 7 byte encoding of the address to dst. The flag sets the
+// most significant bit of the SSID byte, which holds either the
+// command/response bit or the has-been-repeated bit. The last address
+// in the address field has its extension bit set.
+func (a Address) appendBytes(dst []byte, flag bool, last bool) []byte {
+	for i := 0; i < MAX_CALLSIGN_LENGTH; i++ {
+		c := byte(' ')
+		if i < len(a.Callsign) {
+			c = a.Callsign[i]
+		}
+		dst = append(dst, c<<1)
+	}
+
+	ssid := 0x60 | a.SSID<<1
+	if flag {
+		ssid |= 0x80
+	}
+	if last {
+		ssid |= 0x01
+	}
+	return append(dst, ssid)
+}
+
+// Decode a single address, also returning the flag and extension bits.
+func decodeAddress(v []byte) (Address, bool, bool, error) {
+	if len(v) < ADDRESS_LENGTH_BYTES {
+		return Address{}, false, false, errors.New("address too short")
+	}
+
+	var call []byte
+	for i := 0; i < MAX_CALLSIGN_LENGTH; i++ {
+		if v[i]&0x01 != 0 {
+			return Address{}, false, false, errors.New("unexpected end of address field")
+		}
+		c := v[i] >> 1
+		if c == ' ' {
+			continue
+		}
+		call = append(call, c)
+	}
+
+	ssid := v[6]
+	a := Address{
+		Callsign: string(call),
+		SSID:     (ssid >> 1) & 0x0F,
+	}
+	if err := a.Err(); err != nil {
+		return Address{}, false, false, err
+	}
+
+	return a, ssid&0x80 != 0, ssid&0x01 != 0, nil
+}
@@ -0,0 +1,111 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package ax25
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Address
+		wantErr bool
+	}{
+		{s: "N0CALL", want: Address{Callsign: "N0CALL"}},
+		{s: "n0call-7", want: Address{Callsign: "N0CALL", SSID: 7}},
+		{s: "APRS-0", want: Address{Callsign: "APRS"}},
+		{s: "WIDE1-15", want: Address{Callsign: "WIDE1", SSID: 15}},
+		{s: "", wantErr: true},
+		{s: "TOOLONG", wantErr: true},
+		{s: "N0CALL-16", wantErr: true},
+		{s: "N0CALL-", wantErr: true},
+		{s: "N0CALL-X", wantErr: true},
+		{s: "N0 CAL", wantErr: true},
+	}
+
+	for i, tt := range tests {
+		got, err := ParseAddress(tt.s)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("case %d: expected error", i)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: unexpected result: want=%+v got=%+v", i, tt.want, got)
+		}
+		if got.String() != tt.want.String() {
+			t.Errorf("case %d: unexpected string: %q", i, got.String())
+		}
+	}
+}
+
+func TestAddress_String(t *testing.T) {
+	tests := []struct {
+		addr Address
+		want string
+	}{
+		{addr: Address{Callsign: "N0CALL"}, want: "N0CALL"},
+		{addr: Address{Callsign: "N0CALL", SSID: 7}, want: "N0CALL-7"},
+	}
+
+	for i, tt := range tests {
+		if got := tt.addr.String(); got != tt.want {
+			t.Errorf("case %d: unexpected result: want=%q got=%q", i, tt.want, got)
+		}
+	}
+}
+
+func TestAddress_RoundTrip(t *testing.T) {
+	tests := []struct {
+		addr Address
+		flag bool
+		last bool
+		want []byte
+	}{
+		{
+			addr: Address{Callsign: "APRS"},
+			flag: true,
+			want: []byte{0x82, 0xA0, 0xA4, 0xA6, 0x40, 0x40, 0xE0},
+		},
+		{
+			addr: Address{Callsign: "N0CALL", SSID: 7},
+			last: true,
+			want: []byte{0x9C, 0x60, 0x86, 0x82, 0x98, 0x98, 0x6F},
+		},
+	}
+
+	for i, tt := range tests {
+		got := tt.addr.appendBytes(nil, tt.flag, tt.last)
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: unexpected result: want=% x got=% x", i, tt.want, got)
+			continue
+		}
+
+		addr, flag, last, err := decodeAddress(got)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if addr != tt.addr || flag != tt.flag || last != tt.last {
+			t.Errorf("case %d: unexpected decode: addr=%+v flag=%v last=%v", i, addr, flag, last)
+		}
+	}
+}
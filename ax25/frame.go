@@ -0,0 +1,286 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package ax25
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Protocol identifiers carried by I and UI frames.
+const (
+	PID_NO_LAYER_3 = 0xF0
+	PID_IP         = 0xCC
+	PID_ARP        = 0xCD
+	PID_NETROM     = 0xCF
+)
+
+// Identifies the format of a frame, as determined by its control field.
+type FrameType int
+
+const (
+	FRAME_TYPE_I FrameType = iota
+	FRAME_TYPE_S
+	FRAME_TYPE_U
+)
+
+// Supervisory frame types, as encoded in bits 2-3 of the control field.
+type SType uint8
+
+const (
+	S_TYPE_RR   SType = 0x00
+	S_TYPE_RNR  SType = 0x04
+	S_TYPE_REJ  SType = 0x08
+	S_TYPE_SREJ SType = 0x0C
+)
+
+// Unnumbered frame types, as encoded in the control field with the
+// poll/final bit cleared.
+type UType uint8
+
+const (
+	U_TYPE_UI    UType = 0x03
+	U_TYPE_DM    UType = 0x0F
+	U_TYPE_SABM  UType = 0x2F
+	U_TYPE_DISC  UType = 0x43
+	U_TYPE_UA    UType = 0x63
+	U_TYPE_SABME UType = 0x6F
+	U_TYPE_FRMR  UType = 0x87
+	U_TYPE_XID   UType = 0xAF
+	U_TYPE_TEST  UType = 0xE3
+)
+
+// Mask of the poll/final bit within the control field.
+const POLL_FINAL = 0x10
+
+// Build the control field of an I frame.
+func IControl(ns, nr uint8, poll bool) byte {
+	c := (nr&0x07)<<5 | (ns&0x07)<<1
+	if poll {
+		c |= POLL_FINAL
+	}
+	return c
+}
+
+// Build the control field of an S frame.
+func SControl(t SType, nr uint8, pollFinal bool) byte {
+	c := (nr&0x07)<<5 | byte(t) | 0x01
+	if pollFinal {
+		c |= POLL_FINAL
+	}
+	return c
+}
+
+// Build the control field of a U frame.
+func UControl(t UType, pollFinal bool) byte {
+	c := byte(t)
+	if pollFinal {
+		c |= POLL_FINAL
+	}
+	return c
+}
+
+// A digipeater in the path of a frame.
+type Digipeater struct {
+	Address
+
+	// Set by the digipeater once it has repeated the frame
+	Repeated bool
+}
+
+// A single AX.25 frame, not including flags or FCS.
+type Frame struct {
+	Destination Address
+	Source      Address
+	Digipeaters []Digipeater
+
+	// Distinguishes commands from responses, as encoded in the
+	// command/response bits of the destination and source addresses.
+	// Versions of AX.25 prior to 2.0 did not make this distinction.
+	Command bool
+
+	Control byte
+
+	// Protocol identifier, only present in I and UI frames.
+	PID byte
+
+	// Information field, present in I, UI, FRMR, XID and TEST frames.
+	Info []byte
+}
+
+func (f *Frame) Type() FrameType {
+	switch {
+	case f.Control&0x01 == 0:
+		return FRAME_TYPE_I
+	case f.Control&0x03 == 0x01:
+		return FRAME_TYPE_S
+	default:
+		return FRAME_TYPE_U
+	}
+}
+
+// Send sequence number of an I frame.
+func (f *Frame) NS() uint8 {
+	return (f.Control >> 1) & 0x07
+}
+
+// Receive sequence number of an I or S frame.
+func (f *Frame) NR() uint8 {
+	return f.Control >> 5
+}
+
+func (f *Frame) PollFinal() bool {
+	return f.Control&POLL_FINAL != 0
+}
+
+// Supervisory type of an S frame.
+func (f *Frame) SType() SType {
+	return SType(f.Control & 0x0C)
+}
+
+// Unnumbered type of a U frame.
+func (f *Frame) UType() UType {
+	return UType(f.Control &^ POLL_FINAL)
+}
+
+// Indicates whether the frame carries a PID.
+func (f *Frame) HasPID() bool {
+	return f.Type() == FRAME_TYPE_I || (f.Type() == FRAME_TYPE_U && f.UType() == U_TYPE_UI)
+}
+
+// Indicates whether the frame may carry an information field.
+func (f *Frame) HasInfo() bool {
+	if f.Type() == FRAME_TYPE_U {
+		switch f.UType() {
+		case U_TYPE_UI, U_TYPE_FRMR, U_TYPE_XID, U_TYPE_TEST:
+			return true
+		}
+		return false
+	}
+	return f.Type() == FRAME_TYPE_I
+}
+
+func (f *Frame) Err() error {
+	if err := f.Destination.Err(); err != nil {
+		return fmt.Errorf("Destination: %v", err)
+	}
+	if err := f.Source.Err(); err != nil {
+		return fmt.Errorf("Source: %v", err)
+	}
+	if len(f.Digipeaters) > MAX_DIGIPEATERS {
+		return errors.New("too many Digipeaters")
+	}
+	for i, d := range f.Digipeaters {
+		if err := d.Err(); err != nil {
+			return fmt.Errorf("Digipeater %d: %v", i, err)
+		}
+	}
+	if len(f.Info) > 0 && !f.HasInfo() {
+		return errors.New("frame type does not carry Info")
+	}
+	return nil
+}
+
+// Returns the encoded length of the frame.
+func (f *Frame) Size() int {
+	n := ADDRESS_LENGTH_BYTES*(2+len(f.Digipeaters)) + 1
+	if f.HasPID() {
+		n++
+	}
+	return n + len(f.Info)
+}
+
+// Append the encoded frame to dst, not including an FCS.
+func AppendEncode(dst []byte, f *Frame) ([]byte, error) {
+	if err := f.Err(); err != nil {
+		return dst, err
+	}
+
+	// command frames set the C bit of the destination, and responses
+	// the C bit of the source
+	dst = f.Destination.appendBytes(dst, f.Command, false)
+	dst = f.Source.appendBytes(dst, !f.Command, len(f.Digipeaters) == 0)
+	for i, d := range f.Digipeaters {
+		dst = d.appendBytes(dst, d.Repeated, i == len(f.Digipeaters)-1)
+	}
+
+	dst = append(dst, f.Control)
+	if f.HasPID() {
+		dst = append(dst, f.PID)
+	}
+	return append(dst, f.Info...), nil
+}
+
+// Decode a frame, not including an FCS. The Info field of the returned
+// frame references the provided data.
+func Decode(v []byte) (Frame, error) {
+	var f Frame
+
+	dest, destC, last, err := decodeAddress(v)
+	if err != nil {
+		return Frame{}, fmt.Errorf("Destination: %v", err)
+	}
+	if last {
+		return Frame{}, errors.New("address field missing Source")
+	}
+	v = v[ADDRESS_LENGTH_BYTES:]
+
+	src, _, last, err := decodeAddress(v)
+	if err != nil {
+		return Frame{}, fmt.Errorf("Source: %v", err)
+	}
+	v = v[ADDRESS_LENGTH_BYTES:]
+
+	f.Destination = dest
+	f.Source = src
+	f.Command = destC
+
+	for !last {
+		if len(f.Digipeaters) == MAX_DIGIPEATERS {
+			return Frame{}, errors.New("too many Digipeaters")
+		}
+
+		var d Digipeater
+		d.Address, d.Repeated, last, err = decodeAddress(v)
+		if err != nil {
+			return Frame{}, fmt.Errorf("Digipeater %d: %v", len(f.Digipeaters), err)
+		}
+		f.Digipeaters = append(f.Digipeaters, d)
+		v = v[ADDRESS_LENGTH_BYTES:]
+	}
+
+	if len(v) < 1 {
+		return Frame{}, errors.New("frame missing control field")
+	}
+	f.Control = v[0]
+	v = v[1:]
+
+	if f.HasPID() {
+		if len(v) < 1 {
+			return Frame{}, errors.New("frame missing PID")
+		}
+		f.PID = v[0]
+		v = v[1:]
+	}
+
+	if len(v) > 0 {
+		if !f.HasInfo() {
+			return Frame{}, errors.New("unexpected data following control field")
+		}
+		f.Info = v
+	}
+
+	return f, nil
+}
@@ -0,0 +1,220 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package ax25
+
+import (
+	"reflect"
+	"testing"
+)
+
+var (
+	testDest = Address{Callsign: "APRS"}
+	testSrc  = Address{Callsign: "N0CALL", SSID: 7}
+)
+
+func TestAppendEncode(t *testing.T) {
+	tests := []struct {
+		frm  Frame
+		want []byte
+	}{
+		// APRS UI command frame
+		{
+			frm: Frame{
+				Destination: testDest,
+				Source:      testSrc,
+				Command:     true,
+				Control:     UControl(U_TYPE_UI, false),
+				PID:         PID_NO_LAYER_3,
+				Info:        []byte("hi"),
+			},
+			want: []byte{
+				0x82, 0xA0, 0xA4, 0xA6, 0x40, 0x40, 0xE0,
+				0x9C, 0x60, 0x86, 0x82, 0x98, 0x98, 0x6F,
+				0x03, 0xF0, 'h', 'i',
+			},
+		},
+
+		// repeated digipeater, response frame
+		{
+			frm: Frame{
+				Destination: testDest,
+				Source:      testSrc,
+				Digipeaters: []Digipeater{
+					{Address: Address{Callsign: "WIDE1", SSID: 1}, Repeated: true},
+				},
+				Control: UControl(U_TYPE_UI, true),
+				PID:     PID_NO_LAYER_3,
+			},
+			want: []byte{
+				0x82, 0xA0, 0xA4, 0xA6, 0x40, 0x40, 0x60,
+				0x9C, 0x60, 0x86, 0x82, 0x98, 0x98, 0xEE,
+				0xAE, 0x92, 0x88, 0x8A, 0x62, 0x40, 0xE3,
+				0x13, 0xF0,
+			},
+		},
+
+		// S frame without PID
+		{
+			frm: Frame{
+				Destination: testDest,
+				Source:      testSrc,
+				Command:     true,
+				Control:     SControl(S_TYPE_RR, 5, true),
+			},
+			want: []byte{
+				0x82, 0xA0, 0xA4, 0xA6, 0x40, 0x40, 0xE0,
+				0x9C, 0x60, 0x86, 0x82, 0x98, 0x98, 0x6F,
+				0xB1,
+			},
+		},
+
+		// I frame
+		{
+			frm: Frame{
+				Destination: testDest,
+				Source:      testSrc,
+				Command:     true,
+				Control:     IControl(2, 3, false),
+				PID:         PID_IP,
+				Info:        []byte{0x45},
+			},
+			want: []byte{
+				0x82, 0xA0, 0xA4, 0xA6, 0x40, 0x40, 0xE0,
+				0x9C, 0x60, 0x86, 0x82, 0x98, 0x98, 0x6F,
+				0x64, 0xCC, 0x45,
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := AppendEncode(nil, &tt.frm)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: unexpected result: want=% x got=% x", i, tt.want, got)
+			continue
+		}
+		if n := tt.frm.Size(); n != len(got) {
+			t.Errorf("case %d: unexpected size: want=%d got=%d", i, len(got), n)
+		}
+
+		frm, err := Decode(got)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.frm, frm) {
+			t.Errorf("case %d: unexpected decode: want=%+v got=%+v", i, tt.frm, frm)
+		}
+	}
+}
+
+func TestAppendEncode_Invalid(t *testing.T) {
+	digis := make([]Digipeater, MAX_DIGIPEATERS+1)
+	for i := range digis {
+		digis[i].Callsign = "WIDE1"
+	}
+
+	tests := []Frame{
+		{Source: testSrc},
+		{Destination: testDest, Source: Address{Callsign: "N0CALL", SSID: 16}},
+		{Destination: testDest, Source: testSrc, Digipeaters: digis},
+		{Destination: testDest, Source: testSrc, Digipeaters: []Digipeater{{}}},
+		{Destination: testDest, Source: testSrc, Control: UControl(U_TYPE_SABM, true), Info: []byte{0x01}},
+	}
+
+	for i, tt := range tests {
+		if _, err := AppendEncode(nil, &tt); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestFrame_Control(t *testing.T) {
+	tests := []struct {
+		control   byte
+		typ       FrameType
+		ns, nr    uint8
+		pollFinal bool
+	}{
+		{control: IControl(7, 1, true), typ: FRAME_TYPE_I, ns: 7, nr: 1, pollFinal: true},
+		{control: IControl(0, 7, false), typ: FRAME_TYPE_I, ns: 0, nr: 7},
+		{control: SControl(S_TYPE_REJ, 4, false), typ: FRAME_TYPE_S, nr: 4},
+		{control: UControl(U_TYPE_DISC, true), typ: FRAME_TYPE_U, pollFinal: true},
+	}
+
+	for i, tt := range tests {
+		f := Frame{Control: tt.control}
+		if f.Type() != tt.typ {
+			t.Errorf("case %d: unexpected type: want=%v got=%v", i, tt.typ, f.Type())
+		}
+		if tt.typ == FRAME_TYPE_I && f.NS() != tt.ns {
+			t.Errorf("case %d: unexpected N(S): want=%d got=%d", i, tt.ns, f.NS())
+		}
+		if tt.typ != FRAME_TYPE_U && f.NR() != tt.nr {
+			t.Errorf("case %d: unexpected N(R): want=%d got=%d", i, tt.nr, f.NR())
+		}
+		if f.PollFinal() != tt.pollFinal {
+			t.Errorf("case %d: unexpected poll/final: want=%v got=%v", i, tt.pollFinal, f.PollFinal())
+		}
+	}
+
+	if f := (Frame{Control: SControl(S_TYPE_SREJ, 0, false)}); f.SType() != S_TYPE_SREJ {
+		t.Errorf("unexpected S type: %x", f.SType())
+	}
+	if f := (Frame{Control: UControl(U_TYPE_UA, true)}); f.UType() != U_TYPE_UA {
+		t.Errorf("unexpected U type: %x", f.UType())
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	valid, err := AppendEncode(nil, &Frame{
+		Destination: testDest,
+		Source:      testSrc,
+		Command:     true,
+		Control:     UControl(U_TYPE_UI, false),
+		PID:         PID_NO_LAYER_3,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lastDest := append([]byte{}, valid...)
+	lastDest[6] |= 0x01
+
+	badCall := append([]byte{}, valid...)
+	badCall[0] = '!' << 1
+
+	disc := append([]byte{}, valid[:14]...)
+	disc = append(disc, UControl(U_TYPE_DISC, false), 0x00)
+
+	tests := [][]byte{
+		{},
+		valid[:10],
+		valid[:14],
+		valid[:15],
+		lastDest,
+		badCall,
+		disc,
+	}
+
+	for i, tt := range tests {
+		if _, err := Decode(tt); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import (
+	"errors"
+	"io"
+
+	"github.com/antaris-inc/go-satcom/internal/bitstream"
+)
+
+// Describes how a received stream is represented.
+type StreamFormat int
+
+const (
+	// Byte-aligned stream. Sync markers are only identified on byte
+	// boundaries. This is the default.
+	STREAM_FORMAT_BYTES = StreamFormat(0)
+
+	// Bitstream packed 8 bits per byte (MSB first) with no assumed
+	// alignment to frame boundaries.
+	STREAM_FORMAT_PACKED_BITS = StreamFormat(1)
+
+	// Bitstream with one bit per byte, held in the LSB. This is the
+	// format produced by most GNU Radio demodulators.
+	STREAM_FORMAT_UNPACKED_BITS = StreamFormat(2)
+)
+
+func (f StreamFormat) Err() error {
+	switch f {
+	case STREAM_FORMAT_BYTES, STREAM_FORMAT_PACKED_BITS, STREAM_FORMAT_UNPACKED_BITS:
+		return nil
+	}
+	return errors.New("unrecognized StreamFormat")
+}
+
+// Reads frames from a source io.Reader, searching for the sync marker at
+// any bit offset. Once a sync marker is located, subsequent reads are
+// re-aligned to it, and further frames are found at the bit immediately
+// following the previous frame.
+//
+// Internally, the source is expanded to one bit per byte such that the
+// byte-oriented frameReader may be used to locate the (expanded) marker.
+type bitFrameReader struct {
+	*frameReader
+
+	// scratch space for expanded bits
+	bits []byte
+
+	// scratch space for peeked data
+	packed []byte
+
+	// skipped bits not yet reported as a whole byte
+	skippedBits int
+}
+
+func newBitFrameReader(src io.Reader, format StreamFormat, syncMarker []byte, frameBufferSize int) *bitFrameReader {
+	syncBits := make([]byte, len(syncMarker)*8)
+	bitstream.Unpack(syncBits, syncMarker)
+
+	bsrc := bitstream.NewUnpackingReader(src, format == STREAM_FORMAT_PACKED_BITS)
+
+	return &bitFrameReader{
+		frameReader: NewFrameReader(bsrc, syncBits, frameBufferSize*8),
+	}
+}
+
+// Continue reading from source until a sync marker is identified at any bit
+// offset. The number of skipped bytes reported is rounded down, with any
+// remaining bits carried forward to the next call. The reported offset is
+// that of the byte containing the first bit of the sync marker.
+func (c *bitFrameReader) Seek() (SyncLock, error) {
+	lock, err := c.frameReader.Seek()
+
+	c.skippedBits += lock.Skipped
+	lock.Skipped = c.skippedBits / 8
+	c.skippedBits = c.skippedBits % 8
+
+	// convert position from bits to (whole) bytes
+	lock.Offset = lock.Offset / 8
+
+	return lock, err
+}
+
+func (c *bitFrameReader) detectInverted() {
+	c.frameReader.detectInverted(0x1)
+}
+
+// Return the leading n bytes relative to the current alignment, without
+// consuming them. The returned slice is only valid until the next operation.
+func (c *bitFrameReader) Peek(n int) ([]byte, error) {
+	bits, err := c.frameReader.Peek(n * 8)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.packed) < n {
+		c.packed = make([]byte, n)
+	}
+	bitstream.Pack(c.packed[:n], bits)
+
+	return c.packed[:n], nil
+}
+
+// Consume n bytes that have already been buffered (i.e. by Peek).
+func (c *bitFrameReader) Discard(n int) error {
+	return c.frameReader.Discard(n * 8)
+}
+
+// Read through enough data from the source to completely fill the provided
+// byte slice, packing received bits into bytes relative to the current
+// alignment.
+func (c *bitFrameReader) Read(dst []byte) (int, error) {
+	want := len(dst) * 8
+	if len(c.bits) < want {
+		c.bits = make([]byte, want)
+	}
+
+	if _, err := c.frameReader.Read(c.bits[:want]); err != nil {
+		return 0, err
+	}
+
+	bitstream.Pack(dst, c.bits[:want])
+
+	return len(dst), nil
+}
@@ -0,0 +1,142 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/antaris-inc/go-satcom/crc"
+	"github.com/antaris-inc/go-satcom/internal/bitstream"
+	"github.com/antaris-inc/go-satcom/satlab"
+)
+
+// Produces an unpacked bitstream from the provided bytes, offset by
+// the given number of leading garbage bits and padded to a byte boundary.
+func makeShiftedBits(shift int, data []byte) []byte {
+	bits := make([]byte, shift, shift+len(data)*8+8)
+	for i := range bits {
+		bits[i] = byte(i % 2)
+	}
+
+	unpacked := make([]byte, len(data)*8)
+	bitstream.Unpack(unpacked, data)
+	bits = append(bits, unpacked...)
+
+	for len(bits)%8 != 0 {
+		bits = append(bits, 0)
+	}
+
+	return bits
+}
+
+func TestBitFrameReader(t *testing.T) {
+	syncMarker := []byte{0x1A, 0xCF, 0xFC, 0x1D}
+
+	data := []byte{
+		0x1A, 0xCF, 0xFC, 0x1D, 0x11, 0x22, 0x33,
+		0x1A, 0xCF, 0xFC, 0x1D, 0x44, 0x55, 0x66,
+	}
+
+	for shift := 0; shift < 8; shift++ {
+		bits := makeShiftedBits(shift, data)
+
+		packed := make([]byte, len(bits)/8)
+		bitstream.Pack(packed, bits)
+
+		inputs := map[StreamFormat][]byte{
+			STREAM_FORMAT_PACKED_BITS:   packed,
+			STREAM_FORMAT_UNPACKED_BITS: bits,
+		}
+
+		for format, input := range inputs {
+			rd := newBitFrameReader(bytes.NewBuffer(input), format, syncMarker, 32)
+
+			for _, want := range [][]byte{data[:7], data[7:]} {
+				if _, err := rd.Seek(); err != nil {
+					t.Fatalf("shift %d format %d: unexpected error: %v", shift, format, err)
+				}
+
+				got := make([]byte, len(want))
+				if _, err := rd.Read(got); err != nil {
+					t.Fatalf("shift %d format %d: unexpected error: %v", shift, format, err)
+				}
+
+				if !reflect.DeepEqual(want, got) {
+					t.Errorf("shift %d format %d: unexpected result: want=% x got=% x", shift, format, want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestFrameReceiver_PackedBits(t *testing.T) {
+	crc32Adapter, _ := crc.NewCRC32Adapter(crc.CRC32AdapterConfig{
+		Algorithm: crc.CRC32c,
+	})
+
+	cfg := FrameConfig{
+		FrameSyncMarker:        satlab.SATLAB_ASM,
+		SyncMarkerMaxBitErrors: 2,
+		FrameSize:              6,
+		Adapters: []Adapter{
+			crc32Adapter,
+		},
+		StreamFormat:             STREAM_FORMAT_PACKED_BITS,
+		DetectInvertedSyncMarker: true,
+	}
+
+	data := []byte{
+		0x1A, 0xCF, 0xFC, 0x1D, 0x11, 0x22, 0x1C, 0x80, 0xE0, 0x0D, // good frame
+		0x1A, 0xCF, 0xFC, 0x1C, 0x33, 0x44, 0x03, 0x29, 0x47, 0x6b, // good frame, ASM w/ bit error
+		0xE5, 0x30, 0x03, 0xE2, 0xEE, 0xDD, 0xE3, 0x7F, 0x1F, 0xF2, // inverted good frame
+	}
+
+	bits := makeShiftedBits(5, data)
+	input := make([]byte, len(bits)/8)
+	bitstream.Pack(input, bits)
+
+	fr, err := NewFrameReceiver(cfg, bytes.NewBuffer(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgC := make(chan []byte, 10)
+	errC := make(chan error, 10)
+
+	fr.Receive(context.Background(), msgC, errC)
+	close(msgC)
+	close(errC)
+
+	msgs := [][]byte{}
+	for msg := range msgC {
+		msgs = append(msgs, msg)
+	}
+
+	wantMessages := [][]byte{
+		[]byte{0x11, 0x22},
+		[]byte{0x33, 0x44},
+		[]byte{0x11, 0x22},
+	}
+	if !reflect.DeepEqual(wantMessages, msgs) {
+		t.Errorf("unexpected messages: want=% x got=% x", wantMessages, msgs)
+	}
+
+	for err := range errC {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import "sync"
+
+func NewBufferPool(size int) *BufferPool {
+	p := BufferPool{
+		size: size,
+	}
+	p.pool.New = func() interface{} {
+		b := make([]byte, 0, p.size)
+		return &b
+	}
+	return &p
+}
+
+// Manages a set of reusable byte buffers, each with capacity for at
+// least a single frame, avoiding an allocation per frame. Buffers are
+// passed by pointer so that they may be returned to the pool without
+// allocating. It is safe for concurrent use.
+type BufferPool struct {
+	size int
+	pool sync.Pool
+}
+
+// Return an empty buffer with a capacity of at least the pool's size.
+func (p *BufferPool) Get() *[]byte {
+	b := p.pool.Get().(*[]byte)
+	*b = (*b)[:0]
+	return b
+}
+
+// Return a buffer to the pool once it is no longer referenced. Buffers
+// lacking the capacity for a complete frame are discarded.
+func (p *BufferPool) Put(b *[]byte) {
+	if cap(*b) < p.size {
+		return
+	}
+	p.pool.Put(b)
+}
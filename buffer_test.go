@@ -0,0 +1,42 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import "testing"
+
+func TestBufferPool(t *testing.T) {
+	p := NewBufferPool(16)
+
+	b := p.Get()
+	if len(*b) != 0 || cap(*b) < 16 {
+		t.Fatalf("unexpected buffer: len=%d cap=%d", len(*b), cap(*b))
+	}
+
+	// buffers are always returned empty
+	*b = append(*b, 0x01, 0x02)
+	p.Put(b)
+	if b = p.Get(); len(*b) != 0 {
+		t.Errorf("unexpected buffer length: want=0 got=%d", len(*b))
+	}
+
+	// undersized buffers are never handed out
+	small := make([]byte, 0, 8)
+	p.Put(&small)
+	for i := 0; i < 10; i++ {
+		if b := p.Get(); cap(*b) < 16 {
+			t.Fatalf("unexpected buffer capacity: %d", cap(*b))
+		}
+	}
+}
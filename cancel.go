@@ -0,0 +1,342 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/antaris-inc/go-satcom/internal/iodeadline"
+)
+
+// Setting a deadline in the past immediately unblocks pending operations.
+var aLongTimeAgo = time.Unix(1, 0)
+
+type ioResult struct {
+	n   int
+	err error
+}
+
+// Wraps a source such that a blocked read may be interrupted when a context
+// is cancelled or the owning FrameReceiver is closed. Read deadlines are
+// used if the source supports them. Otherwise, reads made while a cancellable
+// context is bound are performed in the background, and the outcome of an
+// interrupted read is retained for the next call.
+//
+// This is not safe for concurrent use.
+type contextReader struct {
+	src  io.Reader
+	done <-chan struct{}
+
+	// set if the source supports read deadlines
+	deadliner iodeadline.Reader
+
+	// context bound to the current read operation
+	ctx context.Context
+
+	// used to stop a running deadline watcher
+	stop    chan struct{}
+	stopped chan error
+
+	// state of a background read
+	pending  chan ioResult
+	buf      []byte
+	leftover []byte
+	err      error
+}
+
+func newContextReader(src io.Reader, done <-chan struct{}) *contextReader {
+	cr := contextReader{
+		src:  src,
+		done: done,
+		ctx:  context.Background(),
+	}
+	// Some sources, such as an *os.File that is not a pipe or socket,
+	// implement the method but return os.ErrNoDeadline.
+	if d, ok := src.(iodeadline.Reader); ok && d.SetReadDeadline(time.Time{}) == nil {
+		cr.deadliner = d
+	}
+	return &cr
+}
+
+// Associate a context with subsequent reads. If successful, a call to
+// unbind must follow once reading is complete.
+func (c *contextReader) bind(ctx context.Context) error {
+	if c.deadliner == nil {
+		c.ctx = ctx
+		return nil
+	}
+
+	// a zero deadline also clears one left behind by a failed unbind
+	dl, _ := ctx.Deadline()
+	if err := c.deadliner.SetReadDeadline(dl); err != nil {
+		return err
+	}
+
+	// The owner may have been closed since it last checked, in which
+	// case the deadline set above replaced the one set by interrupt.
+	select {
+	case <-c.done:
+		if err := c.interrupt(); err != nil {
+			return err
+		}
+		return ErrReceiverClosed
+	default:
+	}
+
+	c.ctx = ctx
+	if ctx.Done() != nil {
+		c.stop = make(chan struct{})
+		c.stopped = make(chan error, 1)
+		go watchDeadline(ctx, c.deadliner.SetReadDeadline, c.stop, c.stopped)
+	}
+	return nil
+}
+
+// Dissociate the current context, restoring the source to its original state.
+func (c *contextReader) unbind() error {
+	var err error
+	if c.deadliner != nil && c.ctx.Done() != nil {
+		close(c.stop)
+		err = <-c.stopped
+
+		select {
+		case <-c.done:
+		default:
+			if resetErr := c.deadliner.SetReadDeadline(time.Time{}); err == nil {
+				err = resetErr
+			}
+		}
+	}
+
+	c.ctx = context.Background()
+	return err
+}
+
+// Immediately unblock any pending read, if supported by the source.
+func (c *contextReader) interrupt() error {
+	if c.deadliner == nil {
+		return nil
+	}
+	return c.deadliner.SetReadDeadline(aLongTimeAgo)
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if c.deadliner != nil {
+		n, err := c.src.Read(p)
+		if err != nil {
+			err = interruptError(c.ctx, c.done, err)
+		}
+		return n, err
+	}
+
+	// return data from a previous background read first
+	if len(c.leftover) > 0 {
+		n := copy(p, c.leftover)
+		c.leftover = c.leftover[n:]
+		return n, nil
+	} else if c.err != nil {
+		err := c.err
+		c.err = nil
+		return 0, err
+	}
+
+	// there is no need to read in the background if nothing
+	// would interrupt the operation
+	if c.pending == nil {
+		if c.ctx.Done() == nil {
+			return c.src.Read(p)
+		}
+
+		if cap(c.buf) < len(p) {
+			c.buf = make([]byte, len(p))
+		}
+		buf := c.buf[:len(p)]
+
+		res := make(chan ioResult, 1)
+		c.pending = res
+		go func() {
+			n, err := c.src.Read(buf)
+			res <- ioResult{n, err}
+		}()
+	}
+
+	select {
+	case res := <-c.pending:
+		c.pending = nil
+		n := copy(p, c.buf[:res.n])
+		c.leftover = c.buf[n:res.n]
+		if len(c.leftover) > 0 {
+			c.err = res.err
+			return n, nil
+		}
+		return n, res.err
+	case <-c.ctx.Done():
+		return 0, c.ctx.Err()
+	case <-c.done:
+		return 0, ErrReceiverClosed
+	}
+}
+
+// Wraps a destination such that a blocked write may be interrupted when a
+// context is cancelled. Write deadlines are used if the destination supports
+// them. Otherwise, writes made with a cancellable context are performed in
+// the background. An interrupted background write continues to completion,
+// and subsequent writes wait for it to finish.
+//
+// This is not safe for concurrent use.
+type contextWriter struct {
+	dst io.Writer
+
+	// set if the destination supports write deadlines
+	deadliner iodeadline.Writer
+
+	// outcome of a background write
+	pending chan ioResult
+}
+
+func newContextWriter(dst io.Writer) *contextWriter {
+	cw := contextWriter{
+		dst: dst,
+	}
+	if d, ok := dst.(iodeadline.Writer); ok && d.SetWriteDeadline(time.Time{}) == nil {
+		cw.deadliner = d
+	}
+	return &cw
+}
+
+// Write the provided data, returning early if the context is cancelled. The
+// provided slice must not be modified until any interrupted write completes,
+// which is indicated by a subsequent call to write returning.
+func (c *contextWriter) write(ctx context.Context, p []byte) (int, error) {
+	if c.deadliner != nil {
+		return c.writeWithDeadline(ctx, p)
+	}
+
+	// wait for any interrupted write before starting another
+	if c.pending != nil {
+		select {
+		case <-c.pending:
+			c.pending = nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	if ctx.Done() == nil {
+		return c.dst.Write(p)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	res := make(chan ioResult, 1)
+	go func() {
+		n, err := c.dst.Write(p)
+		res <- ioResult{n, err}
+	}()
+
+	select {
+	case r := <-res:
+		return r.n, r.err
+	case <-ctx.Done():
+		c.pending = res
+		return 0, ctx.Err()
+	}
+}
+
+// Indicates an interrupted write may still be reading from its buffer.
+func (c *contextWriter) busy() bool {
+	return c.pending != nil
+}
+
+func (c *contextWriter) writeWithDeadline(ctx context.Context, p []byte) (n int, err error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	// a zero deadline also clears one left behind by a failed reset
+	dl, _ := ctx.Deadline()
+	if err := c.deadliner.SetWriteDeadline(dl); err != nil {
+		return 0, err
+	}
+
+	if ctx.Done() != nil {
+		stop := make(chan struct{})
+		stopped := make(chan error, 1)
+		go watchDeadline(ctx, c.deadliner.SetWriteDeadline, stop, stopped)
+		defer func() {
+			close(stop)
+			watchErr := <-stopped
+			resetErr := c.deadliner.SetWriteDeadline(time.Time{})
+			if err == nil {
+				err = watchErr
+			}
+			if err == nil {
+				err = resetErr
+			}
+		}()
+	}
+
+	n, err = c.dst.Write(p)
+	if err != nil {
+		err = interruptError(ctx, nil, err)
+	}
+	return n, err
+}
+
+// Set a deadline in the past once the context is cancelled, stopping
+// early if the stop channel is closed. The outcome of setting the
+// deadline is sent to stopped, which must be buffered.
+func watchDeadline(ctx context.Context, setDeadline func(time.Time) error, stop <-chan struct{}, stopped chan<- error) {
+	select {
+	case <-ctx.Done():
+		stopped <- setDeadline(aLongTimeAgo)
+	case <-stop:
+		stopped <- nil
+	}
+}
+
+// Translate an error caused by an expired deadline into the error
+// describing why the operation was interrupted.
+func interruptError(ctx context.Context, done <-chan struct{}, err error) error {
+	select {
+	case <-done:
+		return ErrReceiverClosed
+	default:
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	// the deadline may expire just before the context reports it
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		if dl, ok := ctx.Deadline(); ok && !time.Now().Before(dl) {
+			return context.DeadlineExceeded
+		}
+	}
+
+	return err
+}
+
+// Indicates the error was caused by an interrupted operation.
+func isInterrupt(err error) bool {
+	return err == context.Canceled || err == context.DeadlineExceeded || err == ErrReceiverClosed
+}
@@ -0,0 +1,227 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var cancelTestConfig = FrameConfig{
+	FrameSyncMarker: []byte{0xFF},
+	FrameSize:       3,
+}
+
+// Confirms a blocked Next call is interrupted by context cancellation, and
+// that a frame arriving later is still received.
+func testFrameReceiverNextInterrupted(t *testing.T, src io.Reader, dst io.Writer) {
+	fr, err := NewFrameReceiver(cancelTestConfig, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := fr.Next(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Next took too long to return: %v", d)
+	}
+
+	go dst.Write([]byte{0xFF, 0x11, 0x22, 0x33})
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	frm, err := fr.Next(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []byte{0x11, 0x22, 0x33}
+	if !reflect.DeepEqual(want, frm.Payload) {
+		t.Fatalf("unexpected result: want=% x got=% x", want, frm.Payload)
+	}
+}
+
+func TestFrameReceiver_NextInterrupted_NoDeadline(t *testing.T) {
+	src, dst := io.Pipe()
+	defer src.Close()
+	testFrameReceiverNextInterrupted(t, src, dst)
+}
+
+func TestFrameReceiver_NextInterrupted_Deadline(t *testing.T) {
+	src, dst := net.Pipe()
+	defer src.Close()
+	defer dst.Close()
+	testFrameReceiverNextInterrupted(t, src, dst)
+}
+
+// Implement deadline methods as *os.File does for regular files, which
+// must fall back to reading and writing in the background.
+type noDeadlineReader struct {
+	io.Reader
+}
+
+func (r *noDeadlineReader) SetReadDeadline(time.Time) error {
+	return os.ErrNoDeadline
+}
+
+type noDeadlineWriter struct {
+	io.WriteCloser
+}
+
+func (w *noDeadlineWriter) SetWriteDeadline(time.Time) error {
+	return os.ErrNoDeadline
+}
+
+func TestFrameReceiver_NextInterrupted_ErrNoDeadline(t *testing.T) {
+	src, dst := io.Pipe()
+	defer src.Close()
+	testFrameReceiverNextInterrupted(t, &noDeadlineReader{src}, dst)
+}
+
+func TestFrameReceiver_CloseInterruptsNext(t *testing.T) {
+	src, dst := net.Pipe()
+	defer src.Close()
+	defer dst.Close()
+
+	fr, err := NewFrameReceiver(cancelTestConfig, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errC := make(chan error)
+	go func() {
+		_, err := fr.Next(context.Background())
+		errC <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	fr.Close()
+
+	select {
+	case err := <-errC:
+		if err != ErrReceiverClosed {
+			t.Fatalf("expected ErrReceiverClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Next failed to return after Close")
+	}
+}
+
+func TestFrameSender_SendContext(t *testing.T) {
+	tests := []struct {
+		name string
+		pipe func() (io.ReadCloser, io.WriteCloser)
+	}{
+		{
+			name: "NoDeadline",
+			pipe: func() (io.ReadCloser, io.WriteCloser) {
+				return io.Pipe()
+			},
+		},
+		{
+			name: "Deadline",
+			pipe: func() (io.ReadCloser, io.WriteCloser) {
+				return net.Pipe()
+			},
+		},
+		{
+			name: "ErrNoDeadline",
+			pipe: func() (io.ReadCloser, io.WriteCloser) {
+				src, dst := io.Pipe()
+				return src, &noDeadlineWriter{dst}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		src, dst := tt.pipe()
+
+		fs, err := NewFrameSender(cancelTestConfig, dst)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+
+		// nothing is reading, so the write blocks until the deadline
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		if err := fs.SendContext(ctx, []byte{0x11, 0x22, 0x33}); err != context.DeadlineExceeded {
+			t.Errorf("%s: expected context.DeadlineExceeded, got %v", tt.name, err)
+		}
+		cancel()
+
+		if got := fs.Stats().SendErrors; got != 1 {
+			t.Errorf("%s: expected 1 send error, got %d", tt.name, got)
+		}
+
+		// once a reader is available, later sends succeed
+		go io.Copy(io.Discard, src)
+
+		ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+		if err := fs.SendContext(ctx, []byte{0x44, 0x55, 0x66}); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		cancel()
+
+		src.Close()
+		dst.Close()
+	}
+}
+
+// Confirms a close racing with bind still interrupts the read, rather than
+// being overridden by the deadline of the bound context.
+func TestContextReader_CloseBeforeBind(t *testing.T) {
+	src, dst := net.Pipe()
+	defer src.Close()
+	defer dst.Close()
+
+	done := make(chan struct{})
+	cr := newContextReader(src, done)
+
+	// as if Close ran between the caller checking done and binding
+	close(done)
+	cr.interrupt()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	if err := cr.bind(ctx); err != ErrReceiverClosed {
+		t.Fatalf("expected ErrReceiverClosed, got %v", err)
+	}
+
+	// the source must still be interrupted for any other reader
+	errC := make(chan error, 1)
+	go func() {
+		_, err := src.Read(make([]byte, 1))
+		errC <- err
+	}()
+
+	select {
+	case err := <-errC:
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("expected os.ErrDeadlineExceeded, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Read failed to return after close")
+	}
+}
@@ -0,0 +1,175 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package capture records the raw data exchanged with a modem, preserving
+// the time at which it was transferred, so that it may be replayed later
+// (e.g. through a satcom.FrameReceiver) to reproduce issues.
+//
+// A capture file begins with a fixed header holding the time of the first
+// chunk, followed by a sequence of chunk records. Each record holds the
+// time elapsed since the previous chunk (in nanoseconds) and the length of
+// the chunk as uvarints, followed by the chunk data.
+package capture
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	FILE_MAGIC   = "SATCAP"
+	FILE_VERSION = 1
+
+	// Length of the file header: magic, version and start time
+	FILE_HEADER_LENGTH_BYTES = len(FILE_MAGIC) + 1 + 8
+
+	// Largest chunk accepted when reading a capture, guarding
+	// against huge allocations when reading a corrupt file
+	MAX_CHUNK_SIZE = 1 << 24
+)
+
+// Data transferred at a specific time.
+type Chunk struct {
+	Time time.Time
+	Data []byte
+}
+
+// Writes chunks to a capture file. Safe for concurrent use.
+type Writer struct {
+	mu  sync.Mutex
+	dst io.Writer
+
+	// time of the previous chunk, zero until the header is written
+	last time.Time
+
+	buf []byte
+}
+
+func NewWriter(dst io.Writer) *Writer {
+	return &Writer{dst: dst}
+}
+
+// Record a chunk of data transferred at the provided time. The file header
+// is written along with the first chunk. Chunks are expected in time order;
+// a chunk earlier than its predecessor is recorded at the same time.
+func (w *Writer) WriteChunk(t time.Time, p []byte) error {
+	if len(p) > MAX_CHUNK_SIZE {
+		return fmt.Errorf("chunk exceeds max size %d", MAX_CHUNK_SIZE)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf := w.buf[:0]
+	if w.last.IsZero() {
+		buf = append(buf, FILE_MAGIC...)
+		buf = append(buf, FILE_VERSION)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(t.UnixNano()))
+		w.last = t
+	}
+
+	var delta time.Duration
+	if t.After(w.last) {
+		delta = t.Sub(w.last)
+		w.last = t
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(delta))
+	buf = binary.AppendUvarint(buf, uint64(len(p)))
+	buf = append(buf, p...)
+	w.buf = buf
+
+	_, err := w.dst.Write(buf)
+	return err
+}
+
+// Reads chunks from a capture file.
+type Reader struct {
+	src   *bufio.Reader
+	start time.Time
+	last  time.Time
+	buf   []byte
+}
+
+// Read the capture file header from the provided source. io.EOF is
+// returned if the capture is empty.
+func NewReader(src io.Reader) (*Reader, error) {
+	br := bufio.NewReader(src)
+
+	hdr := make([]byte, FILE_HEADER_LENGTH_BYTES)
+	if _, err := io.ReadFull(br, hdr); err != nil {
+		return nil, err
+	}
+
+	if string(hdr[:len(FILE_MAGIC)]) != FILE_MAGIC {
+		return nil, errors.New("not a capture file")
+	}
+	if v := hdr[len(FILE_MAGIC)]; v != FILE_VERSION {
+		return nil, fmt.Errorf("unsupported capture version %d", v)
+	}
+
+	start := time.Unix(0, int64(binary.BigEndian.Uint64(hdr[len(FILE_MAGIC)+1:])))
+	r := Reader{
+		src:   br,
+		start: start,
+		last:  start,
+	}
+	return &r, nil
+}
+
+// Time of the first chunk in the capture.
+func (r *Reader) Start() time.Time {
+	return r.start
+}
+
+// Return the next chunk from the capture, or io.EOF once all chunks have
+// been read. The returned data is only valid until the next call.
+func (r *Reader) Next() (Chunk, error) {
+	delta, err := binary.ReadUvarint(r.src)
+	if err != nil {
+		return Chunk{}, err
+	}
+
+	n, err := binary.ReadUvarint(r.src)
+	if err != nil {
+		return Chunk{}, unexpectedEOF(err)
+	}
+	if n > MAX_CHUNK_SIZE {
+		return Chunk{}, fmt.Errorf("chunk exceeds max size %d", MAX_CHUNK_SIZE)
+	}
+
+	if cap(r.buf) < int(n) {
+		r.buf = make([]byte, n)
+	}
+	r.buf = r.buf[:n]
+	if _, err := io.ReadFull(r.src, r.buf); err != nil {
+		return Chunk{}, unexpectedEOF(err)
+	}
+
+	r.last = r.last.Add(time.Duration(delta))
+	return Chunk{Time: r.last, Data: r.buf}, nil
+}
+
+// A capture ending part way through a chunk record is truncated.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,138 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package capture
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCapture_RoundTrip(t *testing.T) {
+	t0 := time.Unix(1700000000, 123456789)
+
+	tests := []struct {
+		input []Chunk
+		want  []Chunk
+	}{
+		// single chunk
+		{
+			input: []Chunk{
+				{Time: t0, Data: []byte{0x01, 0x02, 0x03}},
+			},
+			want: []Chunk{
+				{Time: t0, Data: []byte{0x01, 0x02, 0x03}},
+			},
+		},
+
+		// multiple chunks, including an empty one
+		{
+			input: []Chunk{
+				{Time: t0, Data: []byte{0x01}},
+				{Time: t0.Add(time.Millisecond), Data: []byte{}},
+				{Time: t0.Add(3 * time.Second), Data: bytes.Repeat([]byte{0xAB}, 300)},
+			},
+			want: []Chunk{
+				{Time: t0, Data: []byte{0x01}},
+				{Time: t0.Add(time.Millisecond), Data: []byte{}},
+				{Time: t0.Add(3 * time.Second), Data: bytes.Repeat([]byte{0xAB}, 300)},
+			},
+		},
+
+		// chunk preceding its predecessor is recorded at the same time
+		{
+			input: []Chunk{
+				{Time: t0, Data: []byte{0x01}},
+				{Time: t0.Add(-time.Second), Data: []byte{0x02}},
+				{Time: t0.Add(time.Second), Data: []byte{0x03}},
+			},
+			want: []Chunk{
+				{Time: t0, Data: []byte{0x01}},
+				{Time: t0, Data: []byte{0x02}},
+				{Time: t0.Add(time.Second), Data: []byte{0x03}},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		var buf bytes.Buffer
+		w := NewWriter(&buf)
+		for _, c := range tt.input {
+			if err := w.WriteChunk(c.Time, c.Data); err != nil {
+				t.Fatalf("case %d: unexpected error: %v", i, err)
+			}
+		}
+
+		r, err := NewReader(&buf)
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+		if !r.Start().Equal(t0) {
+			t.Errorf("case %d: unexpected start: want=%v got=%v", i, t0, r.Start())
+		}
+
+		var got []Chunk
+		for {
+			c, err := r.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("case %d: unexpected error: %v", i, err)
+			}
+			got = append(got, Chunk{Time: c.Time, Data: append([]byte{}, c.Data...)})
+		}
+
+		if len(got) != len(tt.want) {
+			t.Fatalf("case %d: unexpected chunk count: want=%d got=%d", i, len(tt.want), len(got))
+		}
+		for j := range got {
+			if !got[j].Time.Equal(tt.want[j].Time) || !reflect.DeepEqual(got[j].Data, tt.want[j].Data) {
+				t.Errorf("case %d: chunk %d: unexpected result: want=%v/% x got=%v/% x", i, j, tt.want[j].Time, tt.want[j].Data, got[j].Time, got[j].Data)
+			}
+		}
+	}
+}
+
+func TestCapture_ReadErrors(t *testing.T) {
+	var valid bytes.Buffer
+	w := NewWriter(&valid)
+	if err := w.WriteChunk(time.Now(), []byte{0x01, 0x02, 0x03, 0x04}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// reading the header fails
+	for i, input := range [][]byte{
+		{},
+		[]byte("SATCAP"),
+		append([]byte("NOTCAP"), valid.Bytes()[6:]...),
+		append([]byte("SATCAP\x02"), valid.Bytes()[7:]...),
+	} {
+		if _, err := NewReader(bytes.NewReader(input)); err == nil {
+			t.Errorf("case %d: expected header error", i)
+		}
+	}
+
+	// truncated chunk
+	input := valid.Bytes()[:valid.Len()-1]
+	r, err := NewReader(bytes.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := r.Next(); err != io.ErrUnexpectedEOF {
+		t.Errorf("unexpected error: want=%v got=%v", io.ErrUnexpectedEOF, err)
+	}
+}
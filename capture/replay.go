@@ -0,0 +1,135 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package capture
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+type ReplayConfig struct {
+	// Deliver data at the original timing of the capture, rather
+	// than as fast as possible.
+	Realtime bool
+
+	// Multiplier applied to the rate of realtime playback. Defaults
+	// to 1 (original timing) if unset.
+	Speed float64
+}
+
+func (cfg *ReplayConfig) Err() error {
+	if cfg.Speed < 0 {
+		return errors.New("Speed must not be negative")
+	}
+	return nil
+}
+
+// Provides an io.Reader that returns the data held in a capture, suitable
+// as the source of a satcom.FrameReceiver. io.EOF is returned once the
+// capture is exhausted.
+type Replay struct {
+	src   *Reader
+	cfg   ReplayConfig
+	speed float64
+
+	// wall clock time playback began, set on first read
+	began time.Time
+
+	// remainder of the current chunk
+	pending []byte
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func NewReplay(src *Reader, cfg ReplayConfig) (*Replay, error) {
+	if err := cfg.Err(); err != nil {
+		return nil, err
+	}
+
+	speed := cfg.Speed
+	if speed == 0 {
+		speed = 1
+	}
+
+	r := Replay{
+		src:   src,
+		cfg:   cfg,
+		speed: speed,
+		done:  make(chan struct{}),
+	}
+	return &r, nil
+}
+
+// Read data from the capture. In realtime mode, this blocks until the
+// time at which the next chunk was originally received. Chunks are never
+// merged, so each read returns at most one chunk.
+func (r *Replay) Read(p []byte) (int, error) {
+	select {
+	case <-r.done:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+
+	if len(r.pending) == 0 {
+		c, err := r.src.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		if r.cfg.Realtime {
+			if err := r.wait(c.Time); err != nil {
+				return 0, err
+			}
+		}
+
+		r.pending = c.Data
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// Block until the equivalent of the provided capture time during playback.
+func (r *Replay) wait(t time.Time) error {
+	if r.began.IsZero() {
+		r.began = time.Now()
+	}
+
+	offset := time.Duration(float64(t.Sub(r.src.Start())) / r.speed)
+	d := time.Until(r.began.Add(offset))
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-r.done:
+		return io.ErrClosedPipe
+	}
+}
+
+// Stop playback, unblocking any pending Read.
+func (r *Replay) Close() error {
+	r.closeOnce.Do(func() { close(r.done) })
+	return nil
+}
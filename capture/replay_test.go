@@ -0,0 +1,181 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package capture
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	satcom "github.com/antaris-inc/go-satcom"
+	"github.com/antaris-inc/go-satcom/example"
+)
+
+func TestReplay_FrameReceiver(t *testing.T) {
+	cfg, err := example.MakeSatlabSRS4FrameConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgs := [][]byte{
+		[]byte("first"),
+		[]byte("second"),
+		[]byte("third"),
+	}
+
+	// record frames as they are sent
+	var capt bytes.Buffer
+	fs, err := satcom.NewFrameSender(cfg, NewTapWriter(io.Discard, NewWriter(&capt)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, msg := range msgs {
+		if err := fs.Send(msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	cr, err := NewReader(&capt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rp, err := NewReplay(cr, ReplayConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fr, err := satcom.NewFrameReceiver(cfg, rp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer fr.Close()
+
+	for i, want := range msgs {
+		frm, err := fr.Next(context.Background())
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+		if got := frm.Payload[:len(want)]; !reflect.DeepEqual(want, got) {
+			t.Errorf("case %d: unexpected result: want=% x got=% x", i, want, got)
+		}
+	}
+
+	if _, err := fr.Next(context.Background()); err != io.EOF {
+		t.Errorf("unexpected error: want=%v got=%v", io.EOF, err)
+	}
+}
+
+func TestReplay_Realtime(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+
+	var capt bytes.Buffer
+	w := NewWriter(&capt)
+	w.WriteChunk(t0, []byte{0x01})
+	w.WriteChunk(t0.Add(100*time.Millisecond), []byte{0x02})
+	w.WriteChunk(t0.Add(200*time.Millisecond), []byte{0x03})
+
+	tests := []struct {
+		cfg     ReplayConfig
+		elapsed time.Duration
+	}{
+		{
+			cfg:     ReplayConfig{},
+			elapsed: 0,
+		},
+		{
+			cfg:     ReplayConfig{Realtime: true},
+			elapsed: 200 * time.Millisecond,
+		},
+		{
+			cfg:     ReplayConfig{Realtime: true, Speed: 4},
+			elapsed: 50 * time.Millisecond,
+		},
+	}
+
+	for i, tt := range tests {
+		cr, err := NewReader(bytes.NewReader(capt.Bytes()))
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+		rp, err := NewReplay(cr, tt.cfg)
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+
+		start := time.Now()
+		got, err := io.ReadAll(rp)
+		elapsed := time.Since(start)
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+
+		want := []byte{0x01, 0x02, 0x03}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("case %d: unexpected result: want=% x got=% x", i, want, got)
+		}
+
+		if elapsed < tt.elapsed || elapsed > tt.elapsed+time.Second {
+			t.Errorf("case %d: unexpected playback duration: want=%v got=%v", i, tt.elapsed, elapsed)
+		}
+	}
+}
+
+func TestReplay_Close(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+
+	var capt bytes.Buffer
+	w := NewWriter(&capt)
+	w.WriteChunk(t0, []byte{0x01})
+	w.WriteChunk(t0.Add(time.Hour), []byte{0x02})
+
+	cr, err := NewReader(&capt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rp, err := NewReplay(cr, ReplayConfig{Realtime: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	buf := make([]byte, 8)
+	if n, err := rp.Read(buf); n != 1 || err != nil {
+		t.Fatalf("unexpected result: n=%d err=%v", n, err)
+	}
+
+	time.AfterFunc(10*time.Millisecond, func() { rp.Close() })
+	if _, err := rp.Read(buf); err != io.ErrClosedPipe {
+		t.Errorf("unexpected error: want=%v got=%v", io.ErrClosedPipe, err)
+	}
+}
+
+func TestReplayConfig_Err(t *testing.T) {
+	tests := []struct {
+		cfg     ReplayConfig
+		wantErr bool
+	}{
+		{ReplayConfig{}, false},
+		{ReplayConfig{Realtime: true, Speed: 0.5}, false},
+		{ReplayConfig{Realtime: true, Speed: -1}, true},
+	}
+
+	for i, tt := range tests {
+		if err := tt.cfg.Err(); (err != nil) != tt.wantErr {
+			t.Errorf("case %d: unexpected result: wantErr=%v got=%v", i, tt.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package capture
+
+import (
+	"io"
+	"time"
+
+	"github.com/antaris-inc/go-satcom/internal/iodeadline"
+)
+
+// Returns a reader that records all data read from src to the provided
+// capture. Much like io.TeeReader, a failure to record data is returned
+// from Read. Read deadlines are forwarded to src if it supports them.
+func NewTapReader(src io.Reader, w *Writer) io.Reader {
+	tr := tapReader{src: src, w: w}
+	if d, ok := src.(iodeadline.Reader); ok {
+		return &deadlineTapReader{tapReader: tr, d: d}
+	}
+	return &tr
+}
+
+type tapReader struct {
+	src io.Reader
+	w   *Writer
+}
+
+func (t *tapReader) Read(p []byte) (int, error) {
+	n, err := t.src.Read(p)
+	if n > 0 {
+		if werr := t.w.WriteChunk(time.Now(), p[:n]); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+type deadlineTapReader struct {
+	tapReader
+	d iodeadline.Reader
+}
+
+func (t *deadlineTapReader) SetReadDeadline(dl time.Time) error {
+	return t.d.SetReadDeadline(dl)
+}
+
+// Returns a writer that records all data successfully written to dst to
+// the provided capture. A failure to record data is returned from Write.
+// Write deadlines are forwarded to dst if it supports them.
+func NewTapWriter(dst io.Writer, w *Writer) io.Writer {
+	tw := tapWriter{dst: dst, w: w}
+	if d, ok := dst.(iodeadline.Writer); ok {
+		return &deadlineTapWriter{tapWriter: tw, d: d}
+	}
+	return &tw
+}
+
+type tapWriter struct {
+	dst io.Writer
+	w   *Writer
+}
+
+func (t *tapWriter) Write(p []byte) (int, error) {
+	n, err := t.dst.Write(p)
+	if n > 0 {
+		if werr := t.w.WriteChunk(time.Now(), p[:n]); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return n, err
+}
+
+type deadlineTapWriter struct {
+	tapWriter
+	d iodeadline.Writer
+}
+
+func (t *deadlineTapWriter) SetWriteDeadline(dl time.Time) error {
+	return t.d.SetWriteDeadline(dl)
+}
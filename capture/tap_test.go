@@ -0,0 +1,134 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package capture
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/antaris-inc/go-satcom/internal/iodeadline"
+)
+
+// Read all chunks from a capture, joining their data.
+func readCapture(t *testing.T, src io.Reader) ([]Chunk, []byte) {
+	r, err := NewReader(src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var chunks []Chunk
+	var data []byte
+	for {
+		c, err := r.Next()
+		if err == io.EOF {
+			return chunks, data
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		chunks = append(chunks, Chunk{Time: c.Time, Data: append([]byte{}, c.Data...)})
+		data = append(data, c.Data...)
+	}
+}
+
+func TestTapReader(t *testing.T) {
+	input := bytes.Repeat([]byte{0x01, 0x02, 0x03, 0x04, 0x05}, 20)
+
+	var capt bytes.Buffer
+	before := time.Now()
+	tr := NewTapReader(iotest.OneByteReader(bytes.NewReader(input)), NewWriter(&capt))
+
+	got, err := io.ReadAll(tr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(input, got) {
+		t.Errorf("unexpected result: want=% x got=% x", input, got)
+	}
+
+	chunks, recorded := readCapture(t, &capt)
+	if !reflect.DeepEqual(input, recorded) {
+		t.Errorf("unexpected capture: want=% x got=% x", input, recorded)
+	}
+	if len(chunks) != len(input) {
+		t.Errorf("unexpected chunk count: want=%d got=%d", len(input), len(chunks))
+	}
+	if chunks[0].Time.Before(before.Round(0)) {
+		t.Errorf("unexpected chunk time: %v precedes %v", chunks[0].Time, before)
+	}
+}
+
+func TestTapWriter(t *testing.T) {
+	inputs := [][]byte{
+		{0x01, 0x02},
+		{0x03},
+		{0x04, 0x05, 0x06},
+	}
+
+	var dst, capt bytes.Buffer
+	tw := NewTapWriter(&dst, NewWriter(&capt))
+	for _, p := range inputs {
+		if _, err := tw.Write(p); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}
+	if !reflect.DeepEqual(want, dst.Bytes()) {
+		t.Errorf("unexpected result: want=% x got=% x", want, dst.Bytes())
+	}
+
+	chunks, recorded := readCapture(t, &capt)
+	if !reflect.DeepEqual(want, recorded) {
+		t.Errorf("unexpected capture: want=% x got=% x", want, recorded)
+	}
+	if len(chunks) != len(inputs) {
+		t.Errorf("unexpected chunk count: want=%d got=%d", len(inputs), len(chunks))
+	}
+}
+
+func TestTap_ForwardsDeadlines(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+
+	var capt bytes.Buffer
+	w := NewWriter(&capt)
+
+	if _, ok := NewTapReader(a, w).(iodeadline.Reader); !ok {
+		t.Errorf("expected read deadlines to be forwarded")
+	}
+	if _, ok := NewTapWriter(a, w).(iodeadline.Writer); !ok {
+		t.Errorf("expected write deadlines to be forwarded")
+	}
+	if _, ok := NewTapReader(&capt, w).(iodeadline.Reader); ok {
+		t.Errorf("unexpected read deadline support")
+	}
+
+	tr := NewTapReader(a, w).(iodeadline.Reader)
+	tr.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	if _, err := tr.(io.Reader).Read(make([]byte, 1)); !errorsIsTimeout(err) {
+		t.Errorf("expected timeout, got %v", err)
+	}
+}
+
+func errorsIsTimeout(err error) bool {
+	ne, ok := err.(net.Error)
+	return ok && ne.Timeout()
+}
@@ -0,0 +1,239 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Describes the content of idle frames generated by a ContinuousSender.
+type IdleFrameMode int
+
+const (
+	// Idle frames are encoded from an empty payload using the
+	// configured Adapters, such that the receiver may recognise
+	// and discard them (e.g. via FrameConfig.DropIdleFrames).
+	IDLE_FRAME_MODE_EMPTY = IdleFrameMode(0)
+
+	// Idle frames are filled with random data following the sync
+	// marker, bypassing all Adapters. Receivers will typically
+	// discard these as corrupted (e.g. due to a CRC mismatch).
+	IDLE_FRAME_MODE_RANDOM = IdleFrameMode(1)
+)
+
+func (m IdleFrameMode) Err() error {
+	switch m {
+	case IDLE_FRAME_MODE_EMPTY, IDLE_FRAME_MODE_RANDOM:
+		return nil
+	default:
+		return fmt.Errorf("unrecognized IdleFrameMode: %d", m)
+	}
+}
+
+type ContinuousSenderConfig struct {
+	// Framing applied to both messages and idle frames
+	FrameConfig FrameConfig
+
+	// Time between the start of consecutive frames, i.e. the
+	// inverse of the frame rate. If zero, frames are written as
+	// quickly as the destination (and FrameConfig.RateLimit, if
+	// set) allows.
+	FrameInterval time.Duration
+
+	// Content of frames written when no messages are queued
+	IdleFrameMode IdleFrameMode
+
+	// Maximum number of messages waiting to be sent, beyond
+	// which Send will block. Defaults to 1 if unset.
+	QueueSize int
+}
+
+func (cfg *ContinuousSenderConfig) Err() error {
+	if err := cfg.FrameConfig.Err(); err != nil {
+		return err
+	}
+
+	if cfg.FrameInterval < 0 {
+		return errors.New("FrameInterval must not be negative")
+	}
+
+	if err := cfg.IdleFrameMode.Err(); err != nil {
+		return err
+	}
+
+	if cfg.IdleFrameMode == IDLE_FRAME_MODE_RANDOM && cfg.FrameConfig.LengthField != nil {
+		return errors.New("IDLE_FRAME_MODE_RANDOM not supported with LengthField")
+	}
+
+	if cfg.IdleFrameMode == IDLE_FRAME_MODE_EMPTY && !cfg.FrameConfig.variableSize() {
+		if n, _ := cfg.FrameConfig.encodedSize(0); n != cfg.FrameConfig.FrameSize {
+			return errors.New("IDLE_FRAME_MODE_EMPTY requires Adapters that pad an empty payload to FrameSize")
+		}
+	}
+
+	if cfg.QueueSize < 0 {
+		return errors.New("QueueSize must not be negative")
+	}
+
+	return nil
+}
+
+func NewContinuousSender(cfg ContinuousSenderConfig, dst io.Writer) (*ContinuousSender, error) {
+	if err := cfg.Err(); err != nil {
+		return nil, err
+	}
+
+	fs, err := NewFrameSender(cfg.FrameConfig, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sizes alone do not reveal every failure, such as an empty payload
+	// being too small to hold a LengthField, so an idle frame is encoded
+	// up front rather than failing once Run starts.
+	if cfg.IdleFrameMode == IDLE_FRAME_MODE_EMPTY {
+		if err := fs.checkEncode(nil); err != nil {
+			return nil, fmt.Errorf("IDLE_FRAME_MODE_EMPTY cannot encode an empty payload: %v", err)
+		}
+	}
+
+	queueN := cfg.QueueSize
+	if queueN == 0 {
+		queueN = 1
+	}
+
+	cs := ContinuousSender{
+		cfg:   cfg,
+		fs:    fs,
+		queue: make(chan []byte, queueN),
+		rnd:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if cfg.IdleFrameMode == IDLE_FRAME_MODE_RANDOM {
+		cs.idle = make([]byte, cfg.FrameConfig.FrameSize)
+	}
+	return &cs, nil
+}
+
+// Writes frames continuously at a fixed rate, as expected by transceivers
+// such as the Satlab SRS4. Queued messages are sent when available, and
+// idle frames are generated otherwise.
+type ContinuousSender struct {
+	cfg ContinuousSenderConfig
+	fs  *FrameSender
+
+	queue chan []byte
+
+	// Only one call to Run may be active at a time
+	runMu sync.Mutex
+
+	// Used only by Run to generate random idle frames
+	rnd  *rand.Rand
+	idle []byte
+}
+
+// Queue a message to be sent by Run, blocking if the queue is full until
+// space is available or the context is cancelled. The message is copied,
+// so may be reused once Send returns. Messages that cannot be encoded
+// are rejected immediately.
+func (s *ContinuousSender) Send(ctx context.Context, msg []byte) error {
+	if err := s.fs.checkEncode(msg); err != nil {
+		return err
+	}
+
+	cp := make([]byte, len(msg))
+	copy(cp, msg)
+
+	select {
+	case s.queue <- cp:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Write frames to the destination until the context is cancelled or a
+// write fails. Frames that cannot be encoded are counted in SendErrors
+// and skipped without interrupting the stream. Messages still queued
+// when Run returns will be sent by a subsequent call.
+func (s *ContinuousSender) Run(ctx context.Context) error {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	var tick <-chan time.Time
+	if s.cfg.FrameInterval > 0 {
+		ticker := time.NewTicker(s.cfg.FrameInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		if tick != nil {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var err error
+		select {
+		case msg := <-s.queue:
+			err = s.fs.SendContext(ctx, msg)
+		default:
+			err = s.sendIdle(ctx)
+		}
+
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			var encErr encodeError
+			if errors.As(err, &encErr) {
+				continue
+			}
+			return err
+		}
+	}
+}
+
+func (s *ContinuousSender) sendIdle(ctx context.Context) error {
+	var err error
+	switch s.cfg.IdleFrameMode {
+	case IDLE_FRAME_MODE_RANDOM:
+		s.rnd.Read(s.idle)
+		err = s.fs.sendContext(ctx, s.idle, true)
+	default:
+		err = s.fs.sendContext(ctx, nil, false)
+	}
+
+	if err == nil {
+		s.fs.count(&s.fs.stats.IdleFramesSent)
+	}
+	return err
+}
+
+// Returns a snapshot of the sender counters, including both messages
+// and idle frames. This is safe to call while Run is active.
+func (s *ContinuousSender) Stats() SenderStats {
+	return s.fs.Stats()
+}
@@ -0,0 +1,367 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Accepts a limited number of writes, after which the associated context
+// is cancelled and all further writes fail.
+type cancellingWriter struct {
+	bytes.Buffer
+	limit  int
+	cancel func()
+}
+
+func (w *cancellingWriter) Write(p []byte) (int, error) {
+	if w.limit == 0 {
+		w.cancel()
+		return 0, errors.New("write limit reached")
+	}
+	w.limit--
+	return w.Buffer.Write(p)
+}
+
+func TestContinuousSenderConfig_Err(t *testing.T) {
+	frmCfg := FrameConfig{
+		FrameSyncMarker: []byte{0x01, 0x02},
+		FrameSize:       4,
+	}
+
+	srs4Cfg, _ := makeSRS4Stream(t, 0, 0)
+
+	tests := []struct {
+		cfg     ContinuousSenderConfig
+		wantErr bool
+	}{
+		{
+			cfg:     ContinuousSenderConfig{FrameConfig: srs4Cfg},
+			wantErr: false,
+		},
+		// empty idle frames require padding to FrameSize
+		{
+			cfg:     ContinuousSenderConfig{FrameConfig: frmCfg},
+			wantErr: true,
+		},
+		{
+			cfg: ContinuousSenderConfig{
+				FrameConfig:   frmCfg,
+				FrameInterval: time.Millisecond,
+				IdleFrameMode: IDLE_FRAME_MODE_RANDOM,
+				QueueSize:     8,
+			},
+			wantErr: false,
+		},
+		// invalid FrameConfig
+		{
+			cfg:     ContinuousSenderConfig{},
+			wantErr: true,
+		},
+		{
+			cfg: ContinuousSenderConfig{
+				FrameConfig:   frmCfg,
+				FrameInterval: -1,
+			},
+			wantErr: true,
+		},
+		{
+			cfg: ContinuousSenderConfig{
+				FrameConfig:   frmCfg,
+				IdleFrameMode: IdleFrameMode(7),
+			},
+			wantErr: true,
+		},
+		{
+			cfg: ContinuousSenderConfig{
+				FrameConfig: FrameConfig{
+					FrameSyncMarker: []byte{0x01, 0x02},
+					FrameSize:       4,
+					LengthField:     &FrameLengthField{Width: 1},
+				},
+				IdleFrameMode: IDLE_FRAME_MODE_RANDOM,
+			},
+			wantErr: true,
+		},
+		{
+			cfg: ContinuousSenderConfig{
+				FrameConfig: srs4Cfg,
+				QueueSize:   -1,
+			},
+			wantErr: true,
+		},
+	}
+
+	for i, tt := range tests {
+		err := tt.cfg.Err()
+		if tt.wantErr != (err != nil) {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+		}
+	}
+}
+
+func TestContinuousSender_IdleFramesEmpty(t *testing.T) {
+	frmCfg, _ := makeSRS4Stream(t, 0, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dst := &cancellingWriter{limit: 5, cancel: cancel}
+
+	cs, err := NewContinuousSender(ContinuousSenderConfig{FrameConfig: frmCfg}, dst)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the queued message is sent first, followed by idle frames
+	if err := cs.Send(ctx, []byte{0x01, 0x02, 0x03}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := cs.Run(ctx); err != context.Canceled {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantStats := SenderStats{
+		FramesSent:     5,
+		BytesWritten:   5 * 227,
+		SendErrors:     1,
+		IdleFramesSent: 4,
+	}
+	if got := cs.Stats(); !reflect.DeepEqual(wantStats, got) {
+		t.Errorf("unexpected stats: want=%+v got=%+v", wantStats, got)
+	}
+
+	// idle frames are dropped by the receiver
+	frmCfg.DropIdleFrames = true
+	fr, err := NewFrameReceiver(frmCfg, &dst.Buffer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgC := make(chan []byte, 10)
+	errC := make(chan error, 10)
+	fr.Receive(context.Background(), msgC, errC)
+	close(msgC)
+	close(errC)
+
+	msgs := [][]byte{}
+	for msg := range msgC {
+		msgs = append(msgs, msg)
+	}
+	for err := range errC {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	wantMessages := [][]byte{
+		[]byte{0x01, 0x02, 0x03},
+	}
+	if !reflect.DeepEqual(wantMessages, msgs) {
+		t.Errorf("unexpected messages: want=% x got=% x", wantMessages, msgs)
+	}
+
+	if st := fr.Stats(); st.IdleFramesDropped != 4 || st.FramesReceived != 5 {
+		t.Errorf("unexpected stats: %+v", st)
+	}
+}
+
+func TestContinuousSender_IdleFramesRandom(t *testing.T) {
+	frmCfg, _ := makeSRS4Stream(t, 0, 0)
+
+	cfg := ContinuousSenderConfig{
+		FrameConfig:   frmCfg,
+		IdleFrameMode: IDLE_FRAME_MODE_RANDOM,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dst := &cancellingWriter{limit: 3, cancel: cancel}
+
+	cs, err := NewContinuousSender(cfg, dst)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := cs.Run(ctx); err != context.Canceled {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// every frame carries the sync marker followed by FrameSize bytes
+	if got := dst.Len(); got != 3*227 {
+		t.Fatalf("unexpected output length: want=%d got=%d", 3*227, got)
+	}
+	for i := 0; i < 3; i++ {
+		frm := dst.Bytes()[i*227 : (i+1)*227]
+		if !bytes.HasPrefix(frm, frmCfg.FrameSyncMarker) {
+			t.Errorf("frame %d: missing sync marker: % x", i, frm[:4])
+		}
+	}
+
+	if got := cs.Stats().IdleFramesSent; got != 3 {
+		t.Errorf("unexpected idle frames: want=3 got=%d", got)
+	}
+}
+
+func TestContinuousSender_FrameInterval(t *testing.T) {
+	frmCfg, _ := makeSRS4Stream(t, 0, 0)
+
+	cfg := ContinuousSenderConfig{
+		FrameConfig:   frmCfg,
+		FrameInterval: 10 * time.Millisecond,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dst := &cancellingWriter{limit: 5, cancel: cancel}
+
+	cs, err := NewContinuousSender(cfg, dst)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	if err := cs.Run(ctx); err != context.Canceled {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("frames written too quickly: %v", elapsed)
+	}
+}
+
+func TestContinuousSender_Send(t *testing.T) {
+	frmCfg, _ := makeSRS4Stream(t, 0, 0)
+
+	cs, err := NewContinuousSender(ContinuousSenderConfig{FrameConfig: frmCfg}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// messages that cannot be encoded are rejected immediately
+	if err := cs.Send(context.Background(), make([]byte, 218)); err == nil {
+		t.Errorf("expected non-nil error")
+	}
+
+	// messages are copied into the queue
+	msg := []byte{0x01}
+	if err := cs.Send(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg[0] = 0x02
+
+	// the queue is now full, so Send must wait
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := cs.Send(ctx, msg); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if got := <-cs.queue; !reflect.DeepEqual([]byte{0x01}, got) {
+		t.Errorf("unexpected queued message: % x", got)
+	}
+}
+
+// Prefixes each message with its length, for use with a LengthField.
+// Messages beginning with 0xFF are rejected by Wrap, a failure that
+// MessageSize cannot detect.
+type lengthPrefixAdapter struct{}
+
+func (a lengthPrefixAdapter) Wrap(v []byte) ([]byte, error) {
+	if len(v) > 0 && v[0] == 0xFF {
+		return nil, errors.New("reserved leading byte")
+	}
+	return append([]byte{byte(len(v))}, v...), nil
+}
+
+func (a lengthPrefixAdapter) Unwrap(v []byte) ([]byte, error) {
+	return v[1:], nil
+}
+
+func (a lengthPrefixAdapter) MessageSize(n int) (int, error) {
+	return n + 1, nil
+}
+
+func TestNewContinuousSender_IdleFrameEncoding(t *testing.T) {
+	frmCfg := FrameConfig{
+		FrameSyncMarker: []byte{0x01, 0x02},
+		FrameSize:       4,
+		LengthField:     &FrameLengthField{Width: 1, Adjustment: 1},
+	}
+
+	// an empty payload is too small to hold the length field
+	if _, err := NewContinuousSender(ContinuousSenderConfig{FrameConfig: frmCfg}, nil); err == nil {
+		t.Errorf("expected non-nil error")
+	}
+
+	frmCfg.Adapters = []Adapter{lengthPrefixAdapter{}}
+	if _, err := NewContinuousSender(ContinuousSenderConfig{FrameConfig: frmCfg}, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestContinuousSender_EncodeFailure(t *testing.T) {
+	frmCfg := FrameConfig{
+		FrameSyncMarker: []byte{0x01, 0x02},
+		FrameSize:       4,
+		LengthField:     &FrameLengthField{Width: 1, Adjustment: 1},
+		Adapters:        []Adapter{lengthPrefixAdapter{}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dst := &cancellingWriter{limit: 2, cancel: cancel}
+
+	cs, err := NewContinuousSender(ContinuousSenderConfig{FrameConfig: frmCfg, QueueSize: 2}, dst)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// messages failing to encode are reported to the caller
+	if err := cs.Send(ctx, []byte{0xFF}); err == nil {
+		t.Errorf("expected non-nil error")
+	}
+
+	// A message reaching Run that cannot be encoded is skipped, and
+	// the following message and idle frames are still sent.
+	cs.queue <- []byte{0xFF}
+	if err := cs.Send(ctx, []byte{0x01}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := cs.Run(ctx); err != context.Canceled {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []byte{0x01, 0x02, 0x01, 0x01, 0x01, 0x02, 0x00}
+	if got := dst.Bytes(); !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=% x got=% x", want, got)
+	}
+
+	wantStats := SenderStats{
+		FramesSent:     2,
+		BytesWritten:   7,
+		SendErrors:     2,
+		IdleFramesSent: 1,
+	}
+	if got := cs.Stats(); !reflect.DeepEqual(wantStats, got) {
+		t.Errorf("unexpected stats: want=%+v got=%+v", wantStats, got)
+	}
+}
@@ -51,7 +51,14 @@ func (a *CRC16Adapter) MessageSize(n int) (int, error) {
 }
 
 func (a *CRC16Adapter) Wrap(v []byte) ([]byte, error) {
-	return append(v, a.MakeChecksum(v)...), nil
+	return a.AppendWrap(make([]byte, 0, len(v)+CRC16_CHECKSUM_LENGTH_BYTES), v)
+}
+
+// Append the provided message and its checksum to dst.
+func (a *CRC16Adapter) AppendWrap(dst, v []byte) ([]byte, error) {
+	cv := crc16.Checksum(v, a.Table)
+	dst = append(dst, v...)
+	return binary.BigEndian.AppendUint16(dst, cv), nil
 }
 
 func (a *CRC16Adapter) MakeChecksum(v []byte) []byte {
@@ -62,18 +69,21 @@ func (a *CRC16Adapter) MakeChecksum(v []byte) []byte {
 }
 
 func (a *CRC16Adapter) Unwrap(v []byte) ([]byte, error) {
+	return a.UnwrapInPlace(v)
+}
+
+// Verify and strip the checksum, returning a subslice of the provided message.
+func (a *CRC16Adapter) UnwrapInPlace(v []byte) ([]byte, error) {
 	vl := len(v)
 	if vl <= CRC16_CHECKSUM_LENGTH_BYTES {
 		return nil, errors.New("too few bytes for CRC validation")
 	}
 
 	// extract the trailer
-	got := v[vl-CRC16_CHECKSUM_LENGTH_BYTES:]
+	got := binary.BigEndian.Uint16(v[vl-CRC16_CHECKSUM_LENGTH_BYTES:])
 	v = v[:vl-CRC16_CHECKSUM_LENGTH_BYTES]
 
-	want := a.MakeChecksum(v)
-
-	if got[0] != want[0] || got[1] != want[1] {
+	if want := crc16.Checksum(v, a.Table); got != want {
 		return nil, errors.New("CRC checksum mismatch")
 	}
 
@@ -55,6 +55,32 @@ func TestCRC16Adapter_Wrap(t *testing.T) {
 	}
 }
 
+func TestCRC16Adapter_AppendWrap(t *testing.T) {
+	ad, err := NewCRC16Adapter(CRC16AdapterConfig{Algorithm: crc16.CRC16_MAXIM})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// existing contents of dst must be preserved
+	dst := make([]byte, 1, 8)
+	dst[0] = 0xFF
+
+	gotBytes, err := ad.AppendWrap(dst, []byte{0x1, 0x2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantBytes := []byte{0xFF, 0x1, 0x2, 0xae, 0x7f}
+
+	if !reflect.DeepEqual(wantBytes, gotBytes) {
+		t.Errorf("unexpected result: want=% x, got=% x", wantBytes, gotBytes)
+	}
+
+	if &gotBytes[0] != &dst[0] {
+		t.Errorf("expected result to reuse dst")
+	}
+}
+
 func TestCRC16Adapter_VerifySuccess(t *testing.T) {
 	ad, err := NewCRC16Adapter(CRC16AdapterConfig{Algorithm: crc16.CRC16_MAXIM})
 	if err != nil {
@@ -54,13 +54,22 @@ func (a *CRC32Adapter) MessageSize(n int) (int, error) {
 }
 
 func (a *CRC32Adapter) Wrap(v []byte) ([]byte, error) {
+	return a.AppendWrap(make([]byte, 0, len(v)+CRC32_CHECKSUM_LENGTH_BYTES), v)
+}
+
+// Append the provided message and its checksum to dst.
+func (a *CRC32Adapter) AppendWrap(dst, v []byte) ([]byte, error) {
 	cv := crc32.Checksum(v, a.Table)
-	cb := make([]byte, CRC32_CHECKSUM_LENGTH_BYTES)
-	binary.BigEndian.PutUint32(cb, cv)
-	return append(v, cb...), nil
+	dst = append(dst, v...)
+	return binary.BigEndian.AppendUint32(dst, cv), nil
 }
 
 func (a *CRC32Adapter) Unwrap(v []byte) ([]byte, error) {
+	return a.UnwrapInPlace(v)
+}
+
+// Verify and strip the checksum, returning a subslice of the provided message.
+func (a *CRC32Adapter) UnwrapInPlace(v []byte) ([]byte, error) {
 	vl := len(v)
 
 	if vl <= CRC32_CHECKSUM_LENGTH_BYTES {
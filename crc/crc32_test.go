@@ -53,6 +53,43 @@ func TestCRC32Adapter_Wrap(t *testing.T) {
 	}
 }
 
+func TestCRC32Adapter_WrapNoAlias(t *testing.T) {
+	ad, err := NewCRC32Adapter(CRC32AdapterConfig{Algorithm: CRC32c})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// spare capacity in the input must not be written to
+	arg := make([]byte, 2, 8)
+	arg[0], arg[1] = 0x1, 0x2
+
+	if _, err := ad.Wrap(arg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if spare := arg[2:6]; !reflect.DeepEqual(spare, make([]byte, 4)) {
+		t.Errorf("unexpected write to input: % x", spare)
+	}
+}
+
+func TestCRC32Adapter_AppendWrap(t *testing.T) {
+	ad, err := NewCRC32Adapter(CRC32AdapterConfig{Algorithm: CRC32c})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	gotBytes, err := ad.AppendWrap([]byte{0xFF}, []byte{0x1, 0x2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantBytes := []byte{0xFF, 0x1, 0x2, 0x03, 0xf8, 0x9f, 0x52}
+
+	if !reflect.DeepEqual(wantBytes, gotBytes) {
+		t.Errorf("unexpected result: want=% x, got=% x", wantBytes, gotBytes)
+	}
+}
+
 func TestCRC32Adapter_VerifySuccess(t *testing.T) {
 	ad, err := NewCRC32Adapter(CRC32AdapterConfig{Algorithm: CRC32c})
 	if err != nil {
@@ -0,0 +1,37 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package example
+
+import (
+	"github.com/antaris-inc/go-satcom"
+	"github.com/antaris-inc/go-satcom/openlst"
+)
+
+// Builds an example FrameConfig that may be used to exchange
+// OpenLST space packets. Each frame begins with a single byte
+// that holds the packet length, not including the length byte
+// itself. Messages sent and received using this config are fully
+// encoded space packets (see openlst.SpacePacket).
+func MakeOpenLSTFrameConfig() satcom.FrameConfig {
+	return satcom.FrameConfig{
+		FrameSyncMarker: openlst.SPACE_PACKET_ASM,
+		FrameSize:       255,
+		LengthField: &satcom.FrameLengthField{
+			Offset:     0,
+			Width:      1,
+			Adjustment: 1,
+		},
+	}
+}
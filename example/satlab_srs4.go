@@ -25,7 +25,7 @@ func MakeSatlabSRS4FrameConfig() (satcom.FrameConfig, error) {
 	cfg.Adapters = append(
 		cfg.Adapters,
 		&satlab.SpaceframeAdapter{
-			satlab.SpaceframeConfig{
+			SpaceframeConfig: satlab.SpaceframeConfig{
 				Type:            satlab.SPACEFRAME_TYPE_CSP,
 				PayloadDataSize: 217,
 			},
@@ -51,7 +51,8 @@ func MakeSatlabSRS4FrameConfig() (satcom.FrameConfig, error) {
 // Use the example Satlab SRS4 FrameConfig to generate a idleframe using
 // an empty payload. Any additional features will be applied correctly
 // but the SRS4 tranceiver will discard it upon receipt due to a lack
-// of payload data. This does NOT include the ASM
+// of payload data. This does NOT include the ASM. A satcom.ContinuousSender
+// using IDLE_FRAME_MODE_EMPTY generates equivalent idle frames automatically.
 func NewSatlabSRS4IdleFrame_Empty() ([]byte, error) {
 	cfg, err := MakeSatlabSRS4FrameConfig()
 	if err != nil {
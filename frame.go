@@ -16,9 +16,13 @@ package satcom
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"sort"
+	"sync"
+	"time"
 )
 
 // Objects that implement this interface are used as middleware
@@ -34,43 +38,452 @@ type Adapter interface {
 	MessageSize(int) (int, error)
 }
 
+// Adapters that are able to encode and decode without allocating may
+// implement this interface. It is used in place of Wrap and Unwrap
+// by FrameSender and FrameReceiver.
+type AppendAdapter interface {
+	Adapter
+
+	// Append the wrapped form of the given payload to dst, returning
+	// the extended slice. The payload and dst must not overlap.
+	AppendWrap(dst, msg []byte) ([]byte, error)
+
+	// Given a complete message, strip and verify expected envelope,
+	// returning a subslice of the provided message. The message may
+	// be modified in place (e.g. to correct errors).
+	UnwrapInPlace([]byte) ([]byte, error)
+}
+
+// Implemented by framing schemes that delimit frames without relying on a
+// sync marker, such as KISS or HDLC. When set as the Codec of a FrameConfig,
+// frames are encoded and located by the codec, while Adapters continue to
+// be applied to the frame contents.
+type FrameCodec interface {
+	// Return an encoder used to frame all data written by a FrameSender.
+	NewEncoder() FrameEncoder
+
+	// Return a decoder that locates frames within the provided source.
+	NewDecoder(src io.Reader) FrameDecoder
+}
+
+type FrameEncoder interface {
+	// Append the framed form of the given frame contents to dst,
+	// returning the extended slice. Encoders may retain state between
+	// calls, as each frame is written immediately after the last.
+	AppendFrame(dst, frm []byte) ([]byte, error)
+}
+
+type FrameDecoder interface {
+	// Read the contents of the next frame from the source. Errors
+	// describing a malformed frame, after which decoding may continue,
+	// must wrap ErrDecodeFailure. The returned slice is only valid
+	// until the next call.
+	ReadFrame() ([]byte, error)
+}
+
+// Decoders that report metadata while decoding frames, such as the number
+// of symbols corrected by FEC, may implement this interface. It is used in
+// place of ReadFrame during frame reception.
+type AnnotatingFrameDecoder interface {
+	FrameDecoder
+
+	// Read the contents of the next frame as ReadFrame does, along with
+	// a set of Annotations describing it. The returned Annotations are
+	// only valid until the next call.
+	ReadFrameAnnotated() ([]byte, []Annotation, error)
+}
+
+// Describes a frame received by a FrameReceiver, along with
+// metadata gathered during its reception.
+type Frame struct {
+	// Complete frame as read from the stream, including the sync
+	// marker. Polarity is corrected for frames located using an
+	// inverted sync marker. If a Codec is used, this instead holds
+	// the frame contents with the codec's framing removed.
+	Raw []byte
+
+	// Message decoded from the frame by the configured Adapters
+	Payload []byte
+
+	// Position of the sync marker within the received (or sent)
+	// stream, in bytes. Not set for frames received using a Codec.
+	Offset int64
+
+	// Time at which the frame was read from (or written to) the stream
+	Timestamp time.Time
+
+	// Number of bit errors tolerated in the sync marker
+	SyncBitErrors int
+
+	// Indicates the frame was located using an inverted sync marker
+	Inverted bool
+
+	// Metadata reported by the Codec and Adapters while decoding the
+	// frame, such as the number of symbols corrected by FEC.
+	Annotations []Annotation
+}
+
+// Indicates whether a frame was sent or received.
+type FrameDirection int
+
+const (
+	FRAME_DIRECTION_RECEIVED FrameDirection = iota
+	FRAME_DIRECTION_SENT
+)
+
+// Notified of each frame sent by a FrameSender or received by a
+// FrameReceiver, such as to record traffic for later analysis.
+type FrameObserver interface {
+	// Called once a frame has been successfully written or decoded.
+	// The Frame and the slices it references are only valid for the
+	// duration of the call. As the same observer may be used by both
+	// a FrameSender and FrameReceiver, calls may be concurrent.
+	ObserveFrame(FrameDirection, *Frame)
+}
+
+// A single piece of metadata reported by an Adapter.
+type Annotation struct {
+	// Identifies the annotation, such as "rs.corrected_symbols".
+	Name string
+
+	Value interface{}
+}
+
+// Adapters that report metadata while decoding frames may implement
+// this interface. It is used in place of Unwrap during frame reception.
+type AnnotatingAdapter interface {
+	Adapter
+
+	// Given a complete message, strip and verify expected envelope,
+	// describing the operation using a set of Annotations
+	UnwrapAnnotated([]byte) ([]byte, []Annotation, error)
+}
+
+// Describes where the length of a variable-size frame may be found
+// in its leading bytes. The frame is considered to begin immediately
+// following the sync marker.
+type FrameLengthField struct {
+	// Position of the length field relative to the start of the frame.
+	Offset int
+
+	// Size of the length field in bytes: 1, 2 or 4.
+	Width int
+
+	// Byte order used to decode multi-byte length fields. If not set,
+	// binary.BigEndian is assumed.
+	ByteOrder binary.ByteOrder
+
+	// Added to the decoded length value to calculate the full frame
+	// size. For example, OpenLST space packets carry a length that
+	// does not include the length byte itself, requiring an
+	// Adjustment of 1.
+	Adjustment int
+
+	// Optional callback used in place of Width/ByteOrder/Adjustment to
+	// calculate the full frame size. It is provided the leading
+	// Offset+Width bytes of the frame.
+	Decode func([]byte) (int, error)
+}
+
+func (f *FrameLengthField) Err() error {
+	if f.Offset < 0 {
+		return errors.New("Offset must not be negative")
+	}
+
+	if f.Width < 0 {
+		return errors.New("Width must not be negative")
+	}
+
+	if f.Decode == nil {
+		if f.Width != 1 && f.Width != 2 && f.Width != 4 {
+			return errors.New("Width must be 1, 2 or 4")
+		}
+	} else if f.HeaderSize() == 0 {
+		return errors.New("Offset+Width must be greater than 0 when using Decode")
+	}
+
+	return nil
+}
+
+// Returns the number of leading frame bytes required to determine
+// the full frame size.
+func (f *FrameLengthField) HeaderSize() int {
+	return f.Offset + f.Width
+}
+
+// Calculates the full frame size (not including sync marker) using
+// the provided leading bytes of a frame.
+func (f *FrameLengthField) FrameLength(hdr []byte) (int, error) {
+	if len(hdr) < f.HeaderSize() {
+		return 0, errors.New("insufficient data for length field")
+	}
+
+	if f.Decode != nil {
+		return f.Decode(hdr[:f.HeaderSize()])
+	}
+
+	order := f.ByteOrder
+	if order == nil {
+		order = binary.BigEndian
+	}
+
+	fb := hdr[f.Offset:f.HeaderSize()]
+
+	var n int
+	switch f.Width {
+	case 1:
+		n = int(fb[0])
+	case 2:
+		n = int(order.Uint16(fb))
+	case 4:
+		n = int(order.Uint32(fb))
+	default:
+		return 0, errors.New("unsupported length field width")
+	}
+
+	return n + f.Adjustment, nil
+}
+
 type FrameConfig struct {
 	// Byte sequence that designates the start of a
 	// message frame.
 	FrameSyncMarker []byte
 
+	// Maximum number of bit errors tolerated when matching
+	// FrameSyncMarker during frame reception. Must be less than
+	// half the number of bits in FrameSyncMarker.
+	SyncMarkerMaxBitErrors int
+
+	// Also search for the bitwise complement of FrameSyncMarker
+	// during frame reception, as may be caused by BPSK/GMSK phase
+	// ambiguity. Frames located using an inverted marker are
+	// themselves inverted before being decoded.
+	DetectInvertedSyncMarker bool
+
 	// Size of fully encoded messages to be transmitted or
 	// received. It is assumed that either a consant message
 	// size will be used, or some sort of padding will be
 	// applied by an included Adapter. This value does NOT
 	// include the length of the sync marker.
+	//
+	// If LengthField is set, this is instead the maximum
+	// size of a frame.
 	FrameSize int
 
+	// Optional description of a length field carried in each
+	// frame. When set, frames may vary in size up to FrameSize.
+	LengthField *FrameLengthField
+
 	// Adapters apply basic encoding/decoding capabilities
 	// to as messages are converted to and from frames.
 	Adapters []Adapter
+
+	// Representation of the received stream. Bitstream formats
+	// allow frames to be located at any bit offset.
+	StreamFormat StreamFormat
+
+	// When a received frame fails to decode, resume the search
+	// for a sync marker from one byte (or bit, for bitstream
+	// formats) past the start of the failed frame's sync marker.
+	// Otherwise, the entire failed frame is discarded. This limits
+	// the cost of a false sync marker match within frame data.
+	ResyncOnFailure bool
+
+	// Silently discard received frames that decode to an empty
+	// payload, such as the idle frames generated by a
+	// ContinuousSender using IDLE_FRAME_MODE_EMPTY. Idle frames
+	// filled with random data cannot be distinguished from
+	// corrupted frames, so are still reported as decode failures.
+	DropIdleFrames bool
+
+	// Optional limit on the rate at which frames are written.
+	// This only applies to a FrameSender.
+	RateLimit *RateLimit
+
+	// Optional observer of all frames sent or received, including
+	// idle frames.
+	Observer FrameObserver
+
+	// Optional framing scheme used in place of a sync marker, such
+	// as KISS. Frames may then vary in size up to FrameSize, which
+	// does not include any overhead added by the codec. Options
+	// relating to the sync marker or LengthField cannot be used.
+	Codec FrameCodec
 }
 
 func (cfg *FrameConfig) Err() error {
-	if len(cfg.FrameSyncMarker) == 0 {
+	if cfg.Codec != nil {
+		if err := cfg.checkCodec(); err != nil {
+			return err
+		}
+	} else if len(cfg.FrameSyncMarker) == 0 {
 		return errors.New("FrameSyncMarker must be provided")
 	}
 
+	if cfg.SyncMarkerMaxBitErrors < 0 || (cfg.SyncMarkerMaxBitErrors > 0 && cfg.SyncMarkerMaxBitErrors*2 >= len(cfg.FrameSyncMarker)*8) {
+		return errors.New("SyncMarkerMaxBitErrors must be less than half the bits in FrameSyncMarker")
+	}
+
 	if cfg.FrameSize <= 0 {
 		return errors.New("FrameSize must be greater than 0")
 	}
 
+	if err := cfg.StreamFormat.Err(); err != nil {
+		return err
+	}
+
+	if cfg.LengthField != nil {
+		if err := cfg.LengthField.Err(); err != nil {
+			return fmt.Errorf("LengthField: %v", err)
+		}
+		if cfg.LengthField.HeaderSize() > cfg.FrameSize {
+			return errors.New("LengthField must fit within FrameSize")
+		}
+	}
+
+	if err := cfg.checkAdapters(); err != nil {
+		return err
+	}
+
+	if cfg.RateLimit != nil {
+		if err := cfg.RateLimit.Err(); err != nil {
+			return fmt.Errorf("RateLimit: %v", err)
+		}
+		if n := cfg.RateLimit.BurstSize; n > 0 && n < len(cfg.FrameSyncMarker)+cfg.FrameSize {
+			return errors.New("RateLimit BurstSize must fit a complete frame")
+		}
+	}
+
+	return nil
+}
+
+// Confirm no options relating to sync marker framing are used alongside
+// a Codec.
+func (cfg *FrameConfig) checkCodec() error {
+	switch {
+	case len(cfg.FrameSyncMarker) > 0:
+		return errors.New("FrameSyncMarker must not be set when using a Codec")
+	case cfg.SyncMarkerMaxBitErrors != 0:
+		return errors.New("SyncMarkerMaxBitErrors not supported with a Codec")
+	case cfg.DetectInvertedSyncMarker:
+		return errors.New("DetectInvertedSyncMarker not supported with a Codec")
+	case cfg.ResyncOnFailure:
+		return errors.New("ResyncOnFailure not supported with a Codec")
+	case cfg.LengthField != nil:
+		return errors.New("LengthField not supported with a Codec")
+	case cfg.StreamFormat != STREAM_FORMAT_BYTES:
+		return errors.New("StreamFormat not supported with a Codec")
+	}
 	return nil
 }
 
+// Indicates whether frames may be smaller than FrameSize.
+func (cfg *FrameConfig) variableSize() bool {
+	return cfg.LengthField != nil || cfg.Codec != nil
+}
+
+// Confirm the Adapters are able to produce frames consistent with FrameSize,
+// based on the composition of their MessageSize functions.
+func (cfg *FrameConfig) checkAdapters() error {
+	maxN, err := cfg.MaxPayloadSize()
+	if err != nil {
+		return err
+	}
+
+	// Fixed-size frames must be filled by the largest payload
+	if !cfg.variableSize() {
+		if frmN, _ := cfg.encodedSize(maxN); frmN != cfg.FrameSize {
+			return fmt.Errorf("Adapters do not fill FrameSize %d: largest payload encodes as %s", cfg.FrameSize, cfg.describeEncoding(maxN))
+		}
+	}
+
+	return nil
+}
+
+// Determine the size of the largest message that can be encoded within
+// FrameSize by the configured Adapters. The MessageSize of each adapter
+// is assumed to be non-decreasing with respect to payload size.
+func (cfg *FrameConfig) MaxPayloadSize() (int, error) {
+	// index of the first payload size that cannot be encoded
+	n := sort.Search(cfg.FrameSize+1, func(n int) bool {
+		frmN, err := cfg.encodedSize(n)
+		return err != nil || frmN > cfg.FrameSize
+	})
+
+	if n == 0 {
+		return 0, fmt.Errorf("Adapters cannot encode any payload within FrameSize %d: empty payload encodes as %s", cfg.FrameSize, cfg.describeEncoding(0))
+	}
+
+	return n - 1, nil
+}
+
+// Calculate the size of the frame (excluding sync marker) produced by
+// applying all Adapters to a message of the given size.
+func (cfg *FrameConfig) encodedSize(n int) (int, error) {
+	var err error
+	for i, ad := range cfg.Adapters {
+		n, err = ad.MessageSize(n)
+		if err != nil {
+			return 0, fmt.Errorf("adapter %d (%T): %v", i, ad, err)
+		}
+	}
+	return n, nil
+}
+
+// Describe the size of a message of the given size after each adapter is
+// applied, for use in error messages.
+func (cfg *FrameConfig) describeEncoding(n int) string {
+	desc := fmt.Sprintf("%d", n)
+	for _, ad := range cfg.Adapters {
+		var err error
+		n, err = ad.MessageSize(n)
+		if err != nil {
+			return desc + fmt.Sprintf(" -> %T (%v)", ad, err)
+		}
+		desc += fmt.Sprintf(" -> %T %d", ad, n)
+	}
+	return desc + " bytes"
+}
+
+func (cfg *FrameConfig) frameLength(hdr []byte) (int, error) {
+	if cfg.LengthField == nil {
+		return cfg.FrameSize, nil
+	}
+
+	n, err := cfg.LengthField.FrameLength(hdr)
+	if err != nil {
+		return 0, err
+	}
+
+	if n < cfg.LengthField.HeaderSize() || n > cfg.FrameSize {
+		return 0, fmt.Errorf("frame length %d out of range", n)
+	}
+
+	return n, nil
+}
+
 func NewFrameSender(cfg FrameConfig, dst io.Writer) (*FrameSender, error) {
 	if err := cfg.Err(); err != nil {
 		return nil, err
 	}
 
+	maxPayloadN, err := cfg.MaxPayloadSize()
+	if err != nil {
+		return nil, err
+	}
+
 	fs := FrameSender{
-		cfg: cfg,
-		dst: dst,
+		cfg:         cfg,
+		dst:         dst,
+		maxPayloadN: maxPayloadN,
+		cw:          newContextWriter(dst),
+		pool:        NewBufferPool(len(cfg.FrameSyncMarker) + cfg.FrameSize),
+	}
+	if cfg.RateLimit != nil {
+		fs.limiter = newTokenBucket(cfg.RateLimit, len(cfg.FrameSyncMarker)+cfg.FrameSize)
+	}
+	if cfg.Codec != nil {
+		fs.enc = cfg.Codec.NewEncoder()
 	}
 	return &fs, nil
 }
@@ -78,62 +491,534 @@ func NewFrameSender(cfg FrameConfig, dst io.Writer) (*FrameSender, error) {
 type FrameSender struct {
 	cfg FrameConfig
 	dst io.Writer
+
+	// Size of the largest message that may be sent
+	maxPayloadN int
+
+	// Serializes all writes to the destination
+	writeMu sync.Mutex
+	cw      *contextWriter
+
+	// Buffers holding encoded frames while they are written
+	pool *BufferPool
+
+	// Intermediate buffers used while applying adapters, guarded
+	// by writeMu
+	scratch [2][]byte
+
+	// Set only if a RateLimit is configured, guarded by writeMu
+	limiter *tokenBucket
+
+	// Number of bytes written to the destination, guarded by writeMu
+	offset int64
+
+	// Set only if a Codec is configured, guarded by writeMu
+	enc      FrameEncoder
+	unframed []byte
+
+	mu    sync.Mutex
+	stats SenderStats
+}
+
+// Returns the size of the largest message that may be sent.
+func (s *FrameSender) MaxPayloadSize() int {
+	return s.maxPayloadN
 }
 
 func (s *FrameSender) Send(msg []byte) error {
-	frm := msg
-	var err error
-	for _, ad := range s.cfg.Adapters {
-		frm, err = ad.Wrap(frm)
-		if err != nil {
-			return err
+	return s.SendContext(context.Background(), msg)
+}
+
+// Encode and write a single frame, returning early if the context
+// is cancelled or its deadline expires. Write deadlines are used if
+// supported by the destination (e.g. net.Conn or *os.File). For other
+// destinations, an interrupted write will continue in the background
+// and the next call will wait for it to complete.
+//
+// It is safe to call SendContext concurrently, though frames are
+// written to the destination one at a time.
+func (s *FrameSender) SendContext(ctx context.Context, msg []byte) error {
+	return s.sendContext(ctx, msg, false)
+}
+
+func (s *FrameSender) count(c *uint64) {
+	s.mu.Lock()
+	*c += 1
+	s.mu.Unlock()
+}
+
+// Write a single frame. If raw is set, the provided data is written
+// following the sync marker without applying any adapters.
+func (s *FrameSender) sendContext(ctx context.Context, msg []byte, raw bool) error {
+	s.writeMu.Lock()
+	n, err := s.send(ctx, msg, raw)
+	s.writeMu.Unlock()
+
+	s.mu.Lock()
+	s.stats.BytesWritten += uint64(n)
+	if err != nil {
+		s.stats.SendErrors += 1
+	} else {
+		s.stats.FramesSent += 1
+	}
+	s.mu.Unlock()
+
+	return err
+}
+
+// Encode and write a single frame, returning the number of bytes written.
+func (s *FrameSender) send(ctx context.Context, msg []byte, raw bool) (int, error) {
+	// Without a codec, the frame size is known ahead of encoding, so the
+	// rate limit is applied first. The overhead added by a codec depends
+	// on the frame contents, so such frames are encoded beforehand.
+	if s.enc == nil {
+		frmN := len(msg)
+		if !raw {
+			var err error
+			if frmN, err = s.frameSize(len(msg)); err != nil {
+				return 0, encodeError{err}
+			}
+		}
+
+		if err := s.limit(ctx, len(s.cfg.FrameSyncMarker)+frmN); err != nil {
+			return 0, err
 		}
 	}
 
-	frmN := len(frm)
-	if frmN > s.cfg.FrameSize {
-		return errors.New("encoded frame exceeds maximum size")
+	buf := s.pool.Get()
+
+	frm, contents, err := s.build(*buf, msg, raw)
+	*buf = frm
+	if err != nil {
+		s.pool.Put(buf)
+		return 0, encodeError{err}
+	}
+	if s.enc != nil {
+		if err := s.limit(ctx, len(frm)); err != nil {
+			s.pool.Put(buf)
+			return 0, err
+		}
 	}
 
-	frmWithASM := append(s.cfg.FrameSyncMarker, frm...)
-	wantN := len(frmWithASM)
+	n, err := s.cw.write(ctx, frm)
+	offset := s.offset
+	s.offset += int64(n)
+
+	if err == nil && n == len(frm) && s.cfg.Observer != nil {
+		obs := Frame{
+			Raw:       contents,
+			Offset:    offset,
+			Timestamp: time.Now(),
+		}
+		if !raw {
+			obs.Payload = msg
+		}
+		s.cfg.Observer.ObserveFrame(FRAME_DIRECTION_SENT, &obs)
+	}
+
+	// an interrupted write may still be reading from the buffer,
+	// in which case it is simply left to the garbage collector
+	if !s.cw.busy() {
+		s.pool.Put(buf)
+	}
 
-	n, err := s.dst.Write(frmWithASM)
 	if err != nil {
-		return err
+		return n, err
 	}
 
 	// NOTE(bcwaldon): not sure what we should do in this case, so an error
 	// seems most appropriate for now.
-	if n != wantN {
-		return errors.New("partial write")
+	if n != len(frm) {
+		return n, errors.New("partial write")
+	}
+
+	return n, nil
+}
+
+// Describes a frame that could not be encoded, as opposed to one that
+// could not be written, allowing callers to skip it and carry on.
+type encodeError struct {
+	err error
+}
+
+func (e encodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e encodeError) Unwrap() error {
+	return e.err
+}
+
+// Wait until the configured rate limit allows n bytes to be written.
+func (s *FrameSender) limit(ctx context.Context, n int) error {
+	if s.limiter == nil {
+		return nil
+	}
+
+	err := s.limiter.wait(ctx, n, s.cfg.RateLimit.NonBlocking)
+	if err == ErrRateLimited {
+		s.count(&s.stats.RateLimited)
+	}
+	return err
+}
+
+// Append the complete encoded frame to dst. The frame contents, as
+// they would be delivered by a FrameReceiver, are also returned; these
+// only differ from the encoded frame if a Codec is used. If raw is set,
+// the provided data is used as the frame without applying any adapters.
+func (s *FrameSender) build(dst []byte, msg []byte, raw bool) ([]byte, []byte, error) {
+	out := dst
+	if s.enc != nil {
+		out = s.unframed[:0]
+	}
+
+	var err error
+	if raw {
+		out = append(append(out, s.cfg.FrameSyncMarker...), msg...)
+	} else {
+		out, err = s.encode(out, msg, &s.scratch)
+	}
+	if err != nil {
+		return dst, nil, err
+	}
+
+	if s.enc == nil {
+		return out, out, nil
+	}
+
+	s.unframed = out
+	frm, err := s.enc.AppendFrame(dst, out)
+	if err != nil {
+		return dst, nil, fmt.Errorf("codec failure: %v", err)
+	}
+	return frm, out, nil
+}
+
+// Determine whether a message of the given size could be encoded, without
+// encoding it, using the MessageSize of each adapter.
+func (s *FrameSender) checkMessageSize(n int) error {
+	_, err := s.frameSize(n)
+	return err
+}
+
+// Calculate the size of the frame (excluding sync marker) that would be
+// produced by encoding a message of the given size.
+func (s *FrameSender) frameSize(n int) (int, error) {
+	n, err := s.cfg.encodedSize(n)
+	if err != nil {
+		return 0, err
+	}
+
+	if n > s.cfg.FrameSize {
+		return 0, errors.New("encoded frame exceeds maximum size")
+	}
+
+	return n, nil
+}
+
+// Fully encode the provided message without writing it, reporting any
+// failure to do so. Unlike send, this may be called concurrently.
+func (s *FrameSender) checkEncode(msg []byte) error {
+	var scratch [2][]byte
+	frm, err := s.encode(nil, msg, &scratch)
+	if err != nil {
+		return err
+	}
+
+	// a new encoder is used, as encoders may retain state between frames
+	if s.cfg.Codec != nil {
+		if _, err := s.cfg.Codec.NewEncoder().AppendFrame(nil, frm); err != nil {
+			return fmt.Errorf("codec failure: %v", err)
+		}
 	}
 
 	return nil
 }
 
+// Apply all adapters to the provided message, appending the sync marker
+// and resulting frame to dst. Adapters alternate between the provided
+// scratch buffers.
+func (s *FrameSender) encode(dst []byte, msg []byte, scratch *[2][]byte) ([]byte, error) {
+	dst = append(dst, s.cfg.FrameSyncMarker...)
+	syncN := len(dst)
+
+	if len(s.cfg.Adapters) == 0 {
+		dst = append(dst, msg...)
+	}
+
+	// Adapters alternate between the scratch buffers, with the
+	// last writing directly to dst.
+	frm := msg
+	for i, ad := range s.cfg.Adapters {
+		last := i == len(s.cfg.Adapters)-1
+
+		out := dst
+		if !last {
+			out = scratch[i%2][:0]
+		}
+
+		var err error
+		if aad, ok := ad.(AppendAdapter); ok {
+			out, err = aad.AppendWrap(out, frm)
+		} else {
+			var wrapped []byte
+			wrapped, err = ad.Wrap(frm)
+			out = append(out, wrapped...)
+		}
+		if err != nil {
+			return dst, err
+		}
+
+		if last {
+			dst = out
+		} else {
+			scratch[i%2] = out
+			frm = out
+		}
+	}
+
+	frmN := len(dst) - syncN
+	if frmN > s.cfg.FrameSize {
+		return dst, errors.New("encoded frame exceeds maximum size")
+	}
+
+	if s.cfg.LengthField != nil {
+		if frmN < s.cfg.LengthField.HeaderSize() {
+			return dst, errors.New("encoded frame too small for length field")
+		}
+		wantN, err := s.cfg.frameLength(dst[syncN:])
+		if err != nil {
+			return dst, fmt.Errorf("invalid length field: %v", err)
+		}
+		if wantN != frmN {
+			return dst, errors.New("length field does not match encoded frame size")
+		}
+	}
+
+	return dst, nil
+}
+
 func NewFrameReceiver(cfg FrameConfig, src io.Reader) (*FrameReceiver, error) {
 	if err := cfg.Err(); err != nil {
 		return nil, err
 	}
 
 	fr := FrameReceiver{
-		cfg: cfg,
-		src: src,
+		cfg:  cfg,
+		src:  src,
+		done: make(chan struct{}),
 	}
+	fr.cr = newContextReader(src, fr.done)
+	if cfg.Codec != nil {
+		fr.dec = cfg.Codec.NewDecoder(fr.cr)
+	} else {
+		fr.rd = fr.newSyncReader()
+	}
+	fr.stats.AdapterErrors = make([]uint64, len(cfg.Adapters))
 	return &fr, nil
 }
 
+// Returned by a FrameReceiver once it has been closed.
+var ErrReceiverClosed = errors.New("receiver closed")
+
+// Wrapped by errors describing a frame that could not be decoded, after
+// which reception may continue. Check for it using errors.Is.
+var ErrDecodeFailure = errors.New("decode failure")
+
 type FrameReceiver struct {
 	cfg FrameConfig
 	src io.Reader
 
 	// Used to asynchronously communicate errors
 	err error
+
+	// Serializes all reads from the source
+	readMu sync.Mutex
+	cr     *contextReader
+	rd     syncReader
+
+	// Set in place of rd if a Codec is configured
+	dec FrameDecoder
+
+	// Closed when the receiver is closed
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu       sync.Mutex
+	lastLock SyncLock
+	stats    ReceiverStats
+}
+
+// Read and decode the next frame from the source, blocking until a
+// frame is available or an error occurs. This offers a simpler
+// alternative to Receive and ReceiveFrames:
+//
+//	for {
+//		frm, err := fr.Next(ctx)
+//		if err == io.EOF {
+//			break
+//		} else if err != nil {
+//			...
+//		}
+//		...
+//	}
+//
+// The io.EOF error is returned once the source is depleted, and
+// ErrReceiverClosed following a call to Close. The context error is
+// returned if the context is cancelled. Any other error relates to a
+// single frame, and Next may be called again to continue reception.
+//
+// A blocked read from the source is interrupted if the context is
+// cancelled or Close is called. Read deadlines are used if supported by
+// the source (e.g. net.Conn or *os.File). For other sources, reads are
+// made in the background while a cancellable context is in use, and an
+// interrupted read is picked up again by the next call.
+//
+// Next is safe to call concurrently, though frames are read from the
+// source one at a time.
+func (r *FrameReceiver) Next(ctx context.Context) (*Frame, error) {
+	r.readMu.Lock()
+	defer r.readMu.Unlock()
+
+	select {
+	case <-r.done:
+		return nil, ErrReceiverClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if err := r.cr.bind(ctx); err != nil {
+		return nil, err
+	}
+
+	var frm Frame
+	err := r.readNext(&frm)
+	if unbindErr := r.cr.unbind(); err == nil {
+		err = unbindErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &frm, nil
+}
+
+// Behaves like Next, but decodes the next frame into the provided Frame,
+// reusing any memory it references. Once a Frame has been populated, its
+// Raw and Payload slices are only valid until it is passed to NextInto
+// again. This allows frames to be received without allocating.
+func (r *FrameReceiver) NextInto(ctx context.Context, frm *Frame) error {
+	r.readMu.Lock()
+	defer r.readMu.Unlock()
+
+	select {
+	case <-r.done:
+		return ErrReceiverClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if err := r.cr.bind(ctx); err != nil {
+		return err
+	}
+
+	err := r.readNext(frm)
+	if unbindErr := r.cr.unbind(); err == nil {
+		err = unbindErr
+	}
+	return err
+}
+
+// Read the next frame, skipping any idle frames if so configured.
+func (r *FrameReceiver) readNext(frm *Frame) error {
+	for {
+		var err error
+		if r.dec != nil {
+			err = r.readCodecFrame(frm)
+		} else {
+			err = r.readFrame(r.rd, frm)
+		}
+		if err != nil {
+			return err
+		}
+
+		if r.cfg.Observer != nil {
+			r.cfg.Observer.ObserveFrame(FRAME_DIRECTION_RECEIVED, frm)
+		}
+
+		if r.cfg.DropIdleFrames && len(frm.Payload) == 0 {
+			r.count(&r.stats.IdleFramesDropped)
+			continue
+		}
+
+		return nil
+	}
+}
+
+// Stop frame reception. Subsequent calls to Next return
+// ErrReceiverClosed, as does any call blocked reading from the
+// source, and any running Receive or ReceiveFrames call returns.
+// The source itself is not closed.
+//
+// A blocked read can only be interrupted if the source supports
+// read deadlines or a cancellable context was provided.
+func (r *FrameReceiver) Close() error {
+	var err error
+	r.closeOnce.Do(func() {
+		close(r.done)
+		err = r.cr.interrupt()
+	})
+	return err
+}
+
+// Returns details of the most recent sync marker match. This is
+// safe to call while Receive is running.
+func (r *FrameReceiver) LastSyncLock() SyncLock {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastLock
+}
+
+// Increment one of the receiver counters.
+func (r *FrameReceiver) count(c *uint64) {
+	r.mu.Lock()
+	*c += 1
+	r.mu.Unlock()
+}
+
+// Flip all bits in the provided slice.
+func invertBytes(v []byte) {
+	for i := range v {
+		v[i] = ^v[i]
+	}
+}
+
+func (r *FrameReceiver) newSyncReader() syncReader {
+	bufN := 2 * (len(r.cfg.FrameSyncMarker) + r.cfg.FrameSize)
+
+	if r.cfg.StreamFormat == STREAM_FORMAT_BYTES {
+		rd := NewFrameReader(r.cr, r.cfg.FrameSyncMarker, bufN)
+		rd.maxBitErrors = r.cfg.SyncMarkerMaxBitErrors
+		if r.cfg.DetectInvertedSyncMarker {
+			rd.detectInverted(0xFF)
+		}
+		return rd
+	}
+
+	rd := newBitFrameReader(r.cr, r.cfg.StreamFormat, r.cfg.FrameSyncMarker, bufN)
+	rd.maxBitErrors = r.cfg.SyncMarkerMaxBitErrors
+	if r.cfg.DetectInvertedSyncMarker {
+		rd.detectInverted()
+	}
+	return rd
 }
 
 // Forward received frames to provided channel.
 // This function is designed to be called within a goroutine.
+// See Next for a simpler alternative that does not require
+// additional goroutines or channels.
 // A caller must use Receive to actually start reading from
 // the source.
 //
@@ -146,63 +1031,50 @@ type FrameReceiver struct {
 // encountered within the frame processor will be sent to it.
 // This channel is used synchronously, so a caller MUST read
 // from it to unblock frame reception following an error.
+//
+// Only decoded messages are forwarded. See ReceiveFrames for
+// access to the full frame and associated metadata.
 func (r *FrameReceiver) Receive(ctx context.Context, msgC chan<- []byte, errC chan<- error) {
-	frameReader := NewFrameReader(r.src, r.cfg.FrameSyncMarker, 2*r.cfg.FrameSize)
-	wantN := len(r.cfg.FrameSyncMarker) + r.cfg.FrameSize
-
-	readFrame := func() ([]byte, error) { // Seek to next sync marker
-		if err := frameReader.Seek(); err != nil {
-			if err == io.EOF {
-				return nil, err
-			}
-			return nil, fmt.Errorf("read failure: %v", err)
-		}
-
-		// now ready sync marker and full frame
-		frm := make([]byte, wantN)
-		n, err := frameReader.Read(frm)
-		if err != nil {
-			if err == io.EOF {
-				return nil, err
-			}
-
-			return nil, fmt.Errorf("read failure: %v", err)
-		}
-
-		//TODO(bcwaldon): decide whether or not to check for canceled context again
-
-		if n == 0 {
-			return nil, errors.New("read failure: empty read operation")
-		} else if n != wantN {
-			return nil, errors.New("read failure: partial read")
-		}
-
-		// must strip leading sync marker
-		msg := frm[len(r.cfg.FrameSyncMarker):]
-
-		// Apply all adapters in reverse order
-		for i := len(r.cfg.Adapters) - 1; i >= 0; i-- {
-			msg, err = r.cfg.Adapters[i].Unwrap(msg)
-			if err != nil {
-				return nil, fmt.Errorf("decode failure: %v", err)
-
-			}
+	r.receive(ctx, errC, func(frm *Frame) bool {
+		select {
+		// This send op may block, but it is up to the caller to
+		// decide how to handle it.
+		case msgC <- frm.Payload:
+			return true
+		case <-ctx.Done():
+			return false
+		case <-r.done:
+			return false
 		}
+	})
+}
 
-		return msg, nil
-	}
-
-	for {
+// Forward received frames to provided channel, including the raw
+// frame and metadata describing its reception. This behaves in the
+// same manner as Receive in all other respects.
+func (r *FrameReceiver) ReceiveFrames(ctx context.Context, frmC chan<- *Frame, errC chan<- error) {
+	r.receive(ctx, errC, func(frm *Frame) bool {
 		select {
+		case frmC <- frm:
+			return true
 		case <-ctx.Done():
-			return
-		default:
+			return false
+		case <-r.done:
+			return false
 		}
+	})
+}
 
-		msg, err := readFrame()
+// Read frames from the source until it is depleted or the context is
+// cancelled, passing each to the provided deliver function. Reception
+// stops if deliver returns false.
+func (r *FrameReceiver) receive(ctx context.Context, errC chan<- error, deliver func(*Frame) bool) {
+	for {
+		frm, err := r.Next(ctx)
 		if err != nil {
-			// signal to shut down, as the source is depleted
-			if err == io.EOF {
+			// signal to shut down, as the source is depleted or
+			// the receiver is no longer needed
+			if err == io.EOF || err == ErrReceiverClosed || ctx.Err() != nil {
 				return
 			}
 
@@ -212,20 +1084,213 @@ func (r *FrameReceiver) Receive(ctx context.Context, msgC chan<- []byte, errC ch
 			// may block, which could have detrimental effects on
 			// the upstream data source, but does give more control.
 			if errC != nil {
-				errC <- err
+				select {
+				case errC <- err:
+				case <-ctx.Done():
+					return
+				case <-r.done:
+					return
+				}
 			}
 
 			continue
 		}
 
-		select {
-		// This send op may block, but it is up to the caller to
-		// decide how to handle it.
-		case msgC <- msg:
-		case <-ctx.Done():
+		if !deliver(frm) {
 			return
 		}
 	}
+}
+
+// Seek to the next sync marker, then read and decode a single frame.
+func (r *FrameReceiver) readFrame(rd syncReader, frm *Frame) error {
+	lock, err := rd.Seek()
+
+	r.mu.Lock()
+	r.stats.BytesSkipped += uint64(lock.Skipped)
+	if err == nil {
+		r.lastLock = lock
+		r.stats.SyncMarkers += 1
+		r.stats.SyncBitErrors += uint64(lock.BitErrors)
+		if lock.Inverted {
+			r.stats.InvertedSyncMarkers += 1
+		}
+	}
+	r.mu.Unlock()
+
+	if err != nil {
+		if err == io.EOF || isInterrupt(err) {
+			return err
+		}
+		r.count(&r.stats.ReadErrors)
+		return fmt.Errorf("read failure: %v", err)
+	}
+
+	// The sync marker and any leading bytes needed to determine
+	// the size of a frame are read first.
+	syncN := len(r.cfg.FrameSyncMarker)
+	hdrN := syncN
+	if r.cfg.LengthField != nil {
+		hdrN += r.cfg.LengthField.HeaderSize()
+	}
+
+	// Data is only peeked at until the frame is known to be valid,
+	// allowing the search for a sync marker to resume from within
+	// a failed frame.
+	buf := frm.Raw[:cap(frm.Raw)]
+	if len(buf) < syncN+r.cfg.FrameSize {
+		buf = make([]byte, syncN+r.cfg.FrameSize)
+	}
+	if err := r.peek(rd, buf, 0, hdrN); err != nil {
+		return err
+	}
+
+	// polarity must be corrected before any decoding
+	if lock.Inverted {
+		invertBytes(buf[:hdrN])
+	}
+
+	frmN, err := r.cfg.frameLength(buf[syncN:hdrN])
+	if err != nil {
+		r.count(&r.stats.FrameLengthErrors)
+		r.discardFailed(rd, hdrN)
+		return fmt.Errorf("%w: %v", ErrDecodeFailure, err)
+	}
+
+	// then the remainder of the frame
+	if err := r.peek(rd, buf, hdrN, syncN+frmN); err != nil {
+		return err
+	}
+	if lock.Inverted {
+		invertBytes(buf[hdrN : syncN+frmN])
+	}
+
+	*frm = Frame{
+		Raw:           buf[:syncN+frmN],
+		Offset:        lock.Offset,
+		Timestamp:     time.Now(),
+		SyncBitErrors: lock.BitErrors,
+		Inverted:      lock.Inverted,
+		Annotations:   frm.Annotations[:0],
+	}
+
+	// must strip leading sync marker
+	if err := r.unwrap(frm, frm.Raw[syncN:]); err != nil {
+		r.discardFailed(rd, len(frm.Raw))
+		return err
+	}
+
+	if err := rd.Discard(len(frm.Raw)); err != nil {
+		return fmt.Errorf("read failure: %v", err)
+	}
+
+	r.count(&r.stats.FramesReceived)
+
+	return nil
+}
+
+// Read and decode a single frame located by the configured Codec.
+func (r *FrameReceiver) readCodecFrame(frm *Frame) error {
+	var dat []byte
+	var anns []Annotation
+	var err error
+	if dec, ok := r.dec.(AnnotatingFrameDecoder); ok {
+		dat, anns, err = dec.ReadFrameAnnotated()
+	} else {
+		dat, err = r.dec.ReadFrame()
+	}
+	if err != nil {
+		if err == io.EOF || isInterrupt(err) {
+			return err
+		}
+		if errors.Is(err, ErrDecodeFailure) {
+			r.count(&r.stats.CodecErrors)
+			return err
+		}
+		r.count(&r.stats.ReadErrors)
+		return fmt.Errorf("read failure: %v", err)
+	}
+
+	if len(dat) > r.cfg.FrameSize {
+		r.count(&r.stats.FrameLengthErrors)
+		return fmt.Errorf("%w: frame length %d out of range", ErrDecodeFailure, len(dat))
+	}
+
+	*frm = Frame{
+		Raw:         append(frm.Raw[:0], dat...),
+		Timestamp:   time.Now(),
+		Annotations: append(frm.Annotations[:0], anns...),
+	}
 
-	return
+	if err := r.unwrap(frm, frm.Raw); err != nil {
+		return err
+	}
+
+	r.count(&r.stats.FramesReceived)
+
+	return nil
+}
+
+// Apply all adapters in reverse order to the provided frame contents,
+// populating the Payload and Annotations of the frame.
+func (r *FrameReceiver) unwrap(frm *Frame, msg []byte) error {
+	for i := len(r.cfg.Adapters) - 1; i >= 0; i-- {
+		var anns []Annotation
+		var err error
+		if ad, ok := r.cfg.Adapters[i].(AnnotatingAdapter); ok {
+			msg, anns, err = ad.UnwrapAnnotated(msg)
+		} else if ad, ok := r.cfg.Adapters[i].(AppendAdapter); ok {
+			msg, err = ad.UnwrapInPlace(msg)
+		} else {
+			msg, err = r.cfg.Adapters[i].Unwrap(msg)
+		}
+		if err != nil {
+			r.count(&r.stats.AdapterErrors[i])
+			return fmt.Errorf("%w: %v", ErrDecodeFailure, err)
+		}
+		frm.Annotations = append(frm.Annotations, anns...)
+	}
+
+	frm.Payload = msg
+
+	return nil
+}
+
+// Fill buf[from:to] with data from the frame reader without consuming it.
+// The leading data in buf must have already been populated by an earlier
+// call, as the data peeked at always begins at the head of the stream.
+func (r *FrameReceiver) peek(rd syncReader, buf []byte, from, to int) error {
+	if from == to {
+		return nil
+	}
+
+	dat, err := rd.Peek(to)
+	if err != nil {
+		if err == io.EOF || isInterrupt(err) {
+			return err
+		}
+
+		r.count(&r.stats.ReadErrors)
+		return fmt.Errorf("read failure: %v", err)
+	}
+
+	if len(dat) != to {
+		return errors.New("read failure: partial read")
+	}
+
+	copy(buf[from:to], dat[from:to])
+
+	return nil
+}
+
+// Discard a frame that failed to decode. If resynchronization is enabled,
+// only the very start of the sync marker is discarded so that the search
+// for the next sync marker resumes from within the failed frame.
+func (r *FrameReceiver) discardFailed(rd syncReader, n int) {
+	// Both operations act on buffered data, so cannot fail.
+	if r.cfg.ResyncOnFailure {
+		rd.Advance()
+	} else {
+		rd.Discard(n)
+	}
 }
@@ -17,13 +17,352 @@ package satcom
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/antaris-inc/go-satcom/crc"
+	"github.com/antaris-inc/go-satcom/openlst"
 	"github.com/antaris-inc/go-satcom/satlab"
+	"github.com/sigurn/crc16"
 )
 
+func TestFrameLengthField_Err(t *testing.T) {
+	decode := func(hdr []byte) (int, error) {
+		return int(hdr[0]), nil
+	}
+
+	tests := []struct {
+		FrameLengthField
+		want string
+	}{
+		{FrameLengthField{Width: 1}, ""},
+		{FrameLengthField{Offset: 2, Width: 4}, ""},
+		{FrameLengthField{Offset: -1, Width: 1}, "Offset must not be negative"},
+		{FrameLengthField{Width: 3}, "Width must be 1, 2 or 4"},
+		{FrameLengthField{Width: -1}, "Width must not be negative"},
+		{FrameLengthField{Width: 3, Decode: decode}, ""},
+		{FrameLengthField{Offset: 1, Decode: decode}, ""},
+		{FrameLengthField{Decode: decode}, "Offset+Width must be greater than 0 when using Decode"},
+		{FrameLengthField{Offset: 2, Width: -1, Decode: decode}, "Width must not be negative"},
+	}
+
+	for ti, tt := range tests {
+		var got string
+		if err := tt.FrameLengthField.Err(); err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("case %d: unexpected result: want=%q got=%q", ti, tt.want, got)
+		}
+	}
+}
+
+func TestFrameLengthField_FrameLength(t *testing.T) {
+	tests := []struct {
+		FrameLengthField
+		hdr  []byte
+		want int
+	}{
+		// single byte at start of frame
+		{
+			FrameLengthField: FrameLengthField{Width: 1},
+			hdr:              []byte{0x0A},
+			want:             10,
+		},
+
+		// single byte with adjustment
+		{
+			FrameLengthField: FrameLengthField{Width: 1, Adjustment: 1},
+			hdr:              []byte{0x0A},
+			want:             11,
+		},
+
+		// big endian (default) two-byte field with offset
+		{
+			FrameLengthField: FrameLengthField{Offset: 1, Width: 2},
+			hdr:              []byte{0xFF, 0x01, 0x02},
+			want:             258,
+		},
+
+		// little endian two-byte field with negative adjustment
+		{
+			FrameLengthField: FrameLengthField{Width: 2, ByteOrder: binary.LittleEndian, Adjustment: -2},
+			hdr:              []byte{0x01, 0x02},
+			want:             511,
+		},
+
+		// four-byte field
+		{
+			FrameLengthField: FrameLengthField{Width: 4},
+			hdr:              []byte{0x00, 0x00, 0x01, 0x00},
+			want:             256,
+		},
+
+		// custom decode function
+		{
+			FrameLengthField: FrameLengthField{
+				Width: 1,
+				Decode: func(hdr []byte) (int, error) {
+					return int(hdr[0]&0x0F) * 4, nil
+				},
+			},
+			hdr:  []byte{0xF3},
+			want: 12,
+		},
+	}
+
+	for ti, tt := range tests {
+		if err := tt.FrameLengthField.Err(); err != nil {
+			t.Errorf("case %d: unexpected error: %v", ti, err)
+			continue
+		}
+		got, err := tt.FrameLengthField.FrameLength(tt.hdr)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", ti, err)
+		} else if got != tt.want {
+			t.Errorf("case %d: unexpected result: want=%d got=%d", ti, tt.want, got)
+		}
+	}
+}
+
+func TestFrameConfig_Err(t *testing.T) {
+	tests := []struct {
+		FrameConfig
+		wantErr bool
+	}{
+		// fixed size frames
+		{
+			FrameConfig: FrameConfig{FrameSyncMarker: []byte{0xFF}, FrameSize: 4},
+		},
+
+		// missing sync marker
+		{
+			FrameConfig: FrameConfig{FrameSize: 4},
+			wantErr:     true,
+		},
+
+		// missing frame size
+		{
+			FrameConfig: FrameConfig{FrameSyncMarker: []byte{0xFF}},
+			wantErr:     true,
+		},
+
+		// variable size frames
+		{
+			FrameConfig: FrameConfig{
+				FrameSyncMarker: []byte{0xFF},
+				FrameSize:       4,
+				LengthField:     &FrameLengthField{Offset: 2, Width: 2},
+			},
+		},
+
+		// unsupported length field width
+		{
+			FrameConfig: FrameConfig{
+				FrameSyncMarker: []byte{0xFF},
+				FrameSize:       4,
+				LengthField:     &FrameLengthField{Width: 3},
+			},
+			wantErr: true,
+		},
+
+		// sync marker bit error tolerance too large
+		{
+			FrameConfig: FrameConfig{
+				FrameSyncMarker:        []byte{0xFF},
+				SyncMarkerMaxBitErrors: 4,
+				FrameSize:              4,
+			},
+			wantErr: true,
+		},
+
+		// unrecognized stream format
+		{
+			FrameConfig: FrameConfig{
+				FrameSyncMarker: []byte{0xFF},
+				FrameSize:       4,
+				StreamFormat:    StreamFormat(7),
+			},
+			wantErr: true,
+		},
+
+		// length field does not fit within frame
+		{
+			FrameConfig: FrameConfig{
+				FrameSyncMarker: []byte{0xFF},
+				FrameSize:       4,
+				LengthField:     &FrameLengthField{Offset: 3, Width: 2},
+			},
+			wantErr: true,
+		},
+
+		// adapters fill frame
+		{
+			FrameConfig: FrameConfig{
+				FrameSyncMarker: []byte{0xFF},
+				FrameSize:       223,
+				Adapters:        makeSpaceframeStack(217),
+			},
+		},
+
+		// adapters do not fill frame
+		{
+			FrameConfig: FrameConfig{
+				FrameSyncMarker: []byte{0xFF},
+				FrameSize:       223,
+				Adapters:        makeSpaceframeStack(216),
+			},
+			wantErr: true,
+		},
+
+		// adapters exceed frame
+		{
+			FrameConfig: FrameConfig{
+				FrameSyncMarker: []byte{0xFF},
+				FrameSize:       223,
+				Adapters:        makeSpaceframeStack(218),
+			},
+			wantErr: true,
+		},
+
+		// adapters within variable size frame
+		{
+			FrameConfig: FrameConfig{
+				FrameSyncMarker: []byte{0xFF},
+				FrameSize:       250,
+				LengthField:     &FrameLengthField{Width: 1},
+				Adapters:        makeSpaceframeStack(217),
+			},
+		},
+	}
+
+	for ti, tt := range tests {
+		err := tt.FrameConfig.Err()
+		if tt.wantErr && err == nil {
+			t.Errorf("case %d: expected non-nil error", ti)
+		} else if !tt.wantErr && err != nil {
+			t.Errorf("case %d: unexpected error: %v", ti, err)
+		}
+	}
+}
+
+// Build a Satlab Spaceframe adapter stack, as used by the SRS4.
+func makeSpaceframeStack(payloadN int) []Adapter {
+	crc32Adapter, _ := crc.NewCRC32Adapter(crc.CRC32AdapterConfig{
+		Algorithm: crc.CRC32c,
+	})
+	return []Adapter{
+		&satlab.SpaceframeAdapter{
+			SpaceframeConfig: satlab.SpaceframeConfig{
+				Type:            satlab.SPACEFRAME_TYPE_CSP,
+				PayloadDataSize: payloadN,
+			},
+		},
+		crc32Adapter,
+	}
+}
+
+func TestFrameConfig_MaxPayloadSize(t *testing.T) {
+	crc32Adapter, _ := crc.NewCRC32Adapter(crc.CRC32AdapterConfig{
+		Algorithm: crc.CRC32c,
+	})
+
+	tests := []struct {
+		FrameConfig
+		want    int
+		wantErr bool
+	}{
+		// no adapters
+		{
+			FrameConfig: FrameConfig{FrameSize: 8},
+			want:        8,
+		},
+
+		// checksum overhead
+		{
+			FrameConfig: FrameConfig{
+				FrameSize: 8,
+				Adapters:  []Adapter{crc32Adapter, crc32Adapter},
+			},
+			want: 0,
+		},
+
+		// padded frames
+		{
+			FrameConfig: FrameConfig{
+				FrameSize: 223,
+				Adapters:  makeSpaceframeStack(217),
+			},
+			want: 217,
+		},
+
+		// overhead exceeds frame
+		{
+			FrameConfig: FrameConfig{
+				FrameSize: 7,
+				Adapters:  []Adapter{crc32Adapter, crc32Adapter},
+			},
+			wantErr: true,
+		},
+	}
+
+	for ti, tt := range tests {
+		got, err := tt.FrameConfig.MaxPayloadSize()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("case %d: expected non-nil error", ti)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", ti, err)
+		} else if got != tt.want {
+			t.Errorf("case %d: unexpected result: want=%d got=%d", ti, tt.want, got)
+		}
+	}
+}
+
+func TestFrameConfig_ErrExplanation(t *testing.T) {
+	cfg := FrameConfig{
+		FrameSyncMarker: []byte{0xFF},
+		FrameSize:       223,
+		Adapters:        makeSpaceframeStack(216),
+	}
+
+	want := "Adapters do not fill FrameSize 223: largest payload encodes as 216 -> *satlab.SpaceframeAdapter 218 -> *crc.CRC32Adapter 222 bytes"
+	if err := cfg.Err(); err == nil || err.Error() != want {
+		t.Errorf("unexpected error: want=%q got=%v", want, err)
+	}
+}
+
+func TestFrameSender_MaxPayloadSize(t *testing.T) {
+	cfg := FrameConfig{
+		FrameSyncMarker: []byte{0xFF},
+		FrameSize:       223,
+		Adapters:        makeSpaceframeStack(217),
+	}
+
+	fs, err := NewFrameSender(cfg, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := fs.MaxPayloadSize(); got != 217 {
+		t.Errorf("unexpected result: want=217 got=%d", got)
+	}
+	if err := fs.Send(make([]byte, 217)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := fs.Send(make([]byte, 218)); err == nil {
+		t.Errorf("expected non-nil error")
+	}
+}
+
 func TestFrameSender_Success(t *testing.T) {
 	crc32Adapter, _ := crc.NewCRC32Adapter(crc.CRC32AdapterConfig{
 		Algorithm: crc.CRC32c,
@@ -45,6 +384,17 @@ func TestFrameSender_Success(t *testing.T) {
 			want: []byte{0xFF, 0x11, 0x22, 0x33},
 		},
 
+		// Send under length w/o adapters, which is written as-is
+		{
+			FrameConfig: FrameConfig{
+				FrameSyncMarker: []byte{0xFF},
+				FrameSize:       4,
+				Adapters:        nil,
+			},
+			msg:  []byte{0x11, 0x22, 0x33},
+			want: []byte{0xFF, 0x11, 0x22, 0x33},
+		},
+
 		// Send max length w/ one adapter
 		{
 			FrameConfig: FrameConfig{
@@ -65,7 +415,7 @@ func TestFrameSender_Success(t *testing.T) {
 				FrameSize:       10,
 				Adapters: []Adapter{
 					&satlab.SpaceframeAdapter{
-						satlab.SpaceframeConfig{
+						SpaceframeConfig: satlab.SpaceframeConfig{
 							Type:            satlab.SPACEFRAME_TYPE_CSP,
 							PayloadDataSize: 4,
 						},
@@ -82,6 +432,17 @@ func TestFrameSender_Success(t *testing.T) {
 				0xBD, 0x02, 0x11, 0x4E, // CRC checksum
 			},
 		},
+
+		// Send variable length w/ length field
+		{
+			FrameConfig: FrameConfig{
+				FrameSyncMarker: []byte{0xFF},
+				FrameSize:       8,
+				LengthField:     &FrameLengthField{Width: 1, Adjustment: 1},
+			},
+			msg:  []byte{0x02, 0x11, 0x22},
+			want: []byte{0xFF, 0x02, 0x11, 0x22},
+		},
 	}
 
 	for ti, tt := range tests {
@@ -131,6 +492,26 @@ func TestFrameSender_Failure(t *testing.T) {
 			},
 			msg: []byte{0x11, 0x22, 0x33},
 		},
+
+		// Send w/ length field that does not match frame
+		{
+			FrameConfig: FrameConfig{
+				FrameSyncMarker: []byte{0xFF},
+				FrameSize:       8,
+				LengthField:     &FrameLengthField{Width: 1},
+			},
+			msg: []byte{0x05, 0x11, 0x22},
+		},
+
+		// Send w/ length field exceeding max frame size
+		{
+			FrameConfig: FrameConfig{
+				FrameSyncMarker: []byte{0xFF},
+				FrameSize:       4,
+				LengthField:     &FrameLengthField{Width: 1},
+			},
+			msg: []byte{0x05, 0x11, 0x22, 0x33, 0x44},
+		},
 	}
 
 	for ti, tt := range tests {
@@ -287,3 +668,848 @@ func TestFrameReceiver_Success(t *testing.T) {
 		}
 	}
 }
+
+func TestFrameReceiver_LengthField(t *testing.T) {
+	cfg := FrameConfig{
+		FrameSyncMarker: openlst.SPACE_PACKET_ASM,
+		FrameSize:       255,
+		LengthField: &FrameLengthField{
+			Width:      1,
+			Adjustment: 1,
+		},
+	}
+
+	newPacket := func(seq int, dat []byte) []byte {
+		p := openlst.NewSpacePacket(
+			openlst.SpacePacketHeader{
+				SequenceNumber: seq,
+				Destination:    1,
+				CommandNumber:  17,
+			},
+			dat,
+			openlst.SpacePacketFooter{
+				HardwareID: 12,
+			},
+		)
+		return p.ToBytes()
+	}
+
+	wantMessages := [][]byte{
+		newPacket(1, []byte{0x11}),
+		newPacket(2, []byte{0x22, 0x33, 0x44, 0x55, 0x66}),
+		newPacket(3, bytes.Repeat([]byte{0x77}, 200)),
+	}
+
+	input := bytes.NewBuffer(nil)
+	input.Write([]byte{0x01, 0x02, 0x03}) // garbage
+	for _, msg := range wantMessages {
+		input.Write(openlst.SPACE_PACKET_PREAMBLE)
+		input.Write(openlst.SPACE_PACKET_ASM)
+		input.Write(msg)
+	}
+
+	fr, err := NewFrameReceiver(cfg, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgC := make(chan []byte, 10)
+	errC := make(chan error, 10)
+
+	go func() {
+		fr.Receive(context.Background(), msgC, errC)
+		close(msgC)
+		close(errC)
+	}()
+
+	msgs := [][]byte{}
+	for msg := range msgC {
+		msgs = append(msgs, msg)
+	}
+	if !reflect.DeepEqual(wantMessages, msgs) {
+		t.Errorf("unexpected messages: want=% x got=% x", wantMessages, msgs)
+	}
+
+	for err := range errC {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// confirm the received packets are still valid
+	for i, msg := range msgs {
+		var p openlst.SpacePacket
+		if err := p.FromBytes(msg); err != nil {
+			t.Errorf("message %d: unexpected error: %v", i, err)
+		} else if err := p.Err(); err != nil {
+			t.Errorf("message %d: invalid packet: %v", i, err)
+		}
+	}
+}
+
+func TestFrameReceiver_LengthFieldOutOfRange(t *testing.T) {
+	cfg := FrameConfig{
+		FrameSyncMarker: []byte{0xFF},
+		FrameSize:       4,
+		LengthField: &FrameLengthField{
+			Width: 1,
+			Decode: func(hdr []byte) (int, error) {
+				if hdr[0] == 0xEE {
+					return 0, errors.New("bad length")
+				}
+				return int(hdr[0]), nil
+			},
+		},
+	}
+
+	input := []byte{
+		0xFF, 0x09, 0x11, 0x22, // length exceeds FrameSize
+		0xFF, 0x00, // length smaller than length field
+		0xFF, 0xEE, // length cannot be decoded
+		0xFF, 0x03, 0x33, 0x44, // good frame
+	}
+
+	fr, err := NewFrameReceiver(cfg, bytes.NewBuffer(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgC := make(chan []byte, 10)
+	errC := make(chan error, 10)
+
+	go func() {
+		fr.Receive(context.Background(), msgC, errC)
+		close(msgC)
+		close(errC)
+	}()
+
+	msgs := [][]byte{}
+	for msg := range msgC {
+		msgs = append(msgs, msg)
+	}
+	wantMessages := [][]byte{
+		[]byte{0x03, 0x33, 0x44},
+	}
+	if !reflect.DeepEqual(wantMessages, msgs) {
+		t.Errorf("unexpected messages: want=% x got=% x", wantMessages, msgs)
+	}
+
+	errs := []error{}
+	for err := range errC {
+		errs = append(errs, err)
+	}
+	if len(errs) != 3 {
+		t.Errorf("expected 3 errors, got %d", len(errs))
+		t.Logf("errors = %v", errs)
+	}
+}
+
+func TestFrameReceiver_SyncMarkerBitErrors(t *testing.T) {
+	cfg := FrameConfig{
+		FrameSyncMarker:        satlab.SATLAB_ASM,
+		SyncMarkerMaxBitErrors: 3,
+		FrameSize:              3,
+	}
+
+	input := []byte{
+		0x1A, 0xCF, 0xFC, 0x1D, 0x11, 0x22, 0x33, // good ASM
+		0x1A, 0x4F, 0xFC, 0x1C, 0x44, 0x55, 0x66, // ASM w/ 2 bit errors
+		0x1A, 0x4F, 0xF0, 0x1C, 0x77, 0x88, 0x99, // ASM w/ 4 bit errors
+	}
+
+	fr, err := NewFrameReceiver(cfg, bytes.NewBuffer(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgC := make(chan []byte, 10)
+	errC := make(chan error, 10)
+
+	fr.Receive(context.Background(), msgC, errC)
+	close(msgC)
+
+	msgs := [][]byte{}
+	for msg := range msgC {
+		msgs = append(msgs, msg)
+	}
+
+	wantMessages := [][]byte{
+		[]byte{0x11, 0x22, 0x33},
+		[]byte{0x44, 0x55, 0x66},
+	}
+	if !reflect.DeepEqual(wantMessages, msgs) {
+		t.Errorf("unexpected messages: want=% x got=% x", wantMessages, msgs)
+	}
+
+	if lock := fr.LastSyncLock(); lock.BitErrors != 2 {
+		t.Errorf("incorrect bit errors: want=2 got=%d", lock.BitErrors)
+	}
+}
+
+func TestFrameReceiver_ResyncOnFailure(t *testing.T) {
+	crc32Adapter, _ := crc.NewCRC32Adapter(crc.CRC32AdapterConfig{
+		Algorithm: crc.CRC32c,
+	})
+
+	frm, err := crc32Adapter.Wrap([]byte{0x11, 0x22, 0x33})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A false sync marker is immediately followed by a real frame,
+	// such that the real frame falls within the bounds of the false one.
+	input := []byte{0x1A, 0xCF, 0xFC, 0x1D, 0x00}
+	input = append(input, satlab.SATLAB_ASM...)
+	input = append(input, frm...)
+
+	tests := []struct {
+		resync       bool
+		wantMessages [][]byte
+	}{
+		{
+			resync:       false,
+			wantMessages: [][]byte{},
+		},
+		{
+			resync: true,
+			wantMessages: [][]byte{
+				[]byte{0x11, 0x22, 0x33},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		cfg := FrameConfig{
+			FrameSyncMarker: satlab.SATLAB_ASM,
+			FrameSize:       7,
+			Adapters:        []Adapter{crc32Adapter},
+			ResyncOnFailure: tt.resync,
+		}
+
+		fr, err := NewFrameReceiver(cfg, bytes.NewBuffer(input))
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+
+		msgC := make(chan []byte, 10)
+		errC := make(chan error, 10)
+
+		fr.Receive(context.Background(), msgC, errC)
+		close(msgC)
+		close(errC)
+
+		msgs := [][]byte{}
+		for msg := range msgC {
+			msgs = append(msgs, msg)
+		}
+
+		if !reflect.DeepEqual(tt.wantMessages, msgs) {
+			t.Errorf("case %d: unexpected messages: want=% x got=% x", i, tt.wantMessages, msgs)
+		}
+
+		if got := len(errC); got != 1 {
+			t.Errorf("case %d: unexpected error count: want=1 got=%d", i, got)
+		}
+		for err := range errC {
+			if !errors.Is(err, ErrDecodeFailure) {
+				t.Errorf("case %d: unexpected error: %v", i, err)
+			}
+		}
+	}
+}
+
+func TestFrameReceiver_InvertedSyncMarker(t *testing.T) {
+	crc32Adapter, _ := crc.NewCRC32Adapter(crc.CRC32AdapterConfig{
+		Algorithm: crc.CRC32c,
+	})
+
+	cfg := FrameConfig{
+		FrameSyncMarker:          satlab.SATLAB_ASM,
+		DetectInvertedSyncMarker: true,
+		FrameSize:                6,
+		Adapters: []Adapter{
+			crc32Adapter,
+		},
+	}
+
+	frames := []byte{
+		0x1A, 0xCF, 0xFC, 0x1D, 0x11, 0x22, 0x1C, 0x80, 0xE0, 0x0D,
+		0x1A, 0xCF, 0xFC, 0x1D, 0x33, 0x44, 0x03, 0x29, 0x47, 0x6b,
+	}
+
+	// simulate a phase flip for the entire stream
+	input := append([]byte{0x00, 0x01}, frames...)
+	invertBytes(input)
+
+	fr, err := NewFrameReceiver(cfg, bytes.NewBuffer(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgC := make(chan []byte, 10)
+	errC := make(chan error, 10)
+
+	fr.Receive(context.Background(), msgC, errC)
+	close(msgC)
+	close(errC)
+
+	msgs := [][]byte{}
+	for msg := range msgC {
+		msgs = append(msgs, msg)
+	}
+
+	wantMessages := [][]byte{
+		[]byte{0x11, 0x22},
+		[]byte{0x33, 0x44},
+	}
+	if !reflect.DeepEqual(wantMessages, msgs) {
+		t.Errorf("unexpected messages: want=% x got=% x", wantMessages, msgs)
+	}
+
+	for err := range errC {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if lock := fr.LastSyncLock(); !lock.Inverted {
+		t.Errorf("expected inverted sync lock")
+	}
+}
+
+// Strips a single trailing byte, reporting its value as an annotation.
+type trailerAdapter struct{}
+
+func (a *trailerAdapter) Wrap(v []byte) ([]byte, error) {
+	return append(v, 0x00), nil
+}
+
+func (a *trailerAdapter) Unwrap(v []byte) ([]byte, error) {
+	v, _, err := a.UnwrapAnnotated(v)
+	return v, err
+}
+
+func (a *trailerAdapter) UnwrapAnnotated(v []byte) ([]byte, []Annotation, error) {
+	if len(v) == 0 {
+		return nil, nil, errors.New("too few bytes")
+	}
+	anns := []Annotation{
+		{Name: "trailer", Value: int(v[len(v)-1])},
+	}
+	return v[:len(v)-1], anns, nil
+}
+
+func (a *trailerAdapter) MessageSize(n int) (int, error) {
+	return n + 1, nil
+}
+
+func TestFrameReceiver_ReceiveFrames(t *testing.T) {
+	cfg := FrameConfig{
+		FrameSyncMarker:          []byte{0x1A, 0xCF},
+		SyncMarkerMaxBitErrors:   1,
+		DetectInvertedSyncMarker: true,
+		FrameSize:                3,
+		Adapters: []Adapter{
+			&trailerAdapter{},
+		},
+	}
+
+	input := []byte{
+		0x00, 0x00, // garbage
+		0x1A, 0xCF, 0x11, 0x22, 0x07, // good frame
+		0x1B, 0xCF, 0x33, 0x44, 0x09, // ASM w/ bit error
+		0xE5, 0x30, 0xAA, 0x99, 0xF0, // inverted frame
+	}
+
+	fr, err := NewFrameReceiver(cfg, bytes.NewBuffer(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	frmC := make(chan *Frame, 10)
+	errC := make(chan error, 10)
+
+	fr.ReceiveFrames(context.Background(), frmC, errC)
+	close(frmC)
+	close(errC)
+
+	want := []Frame{
+		{
+			Raw:         []byte{0x1A, 0xCF, 0x11, 0x22, 0x07},
+			Payload:     []byte{0x11, 0x22},
+			Offset:      2,
+			Annotations: []Annotation{{Name: "trailer", Value: 7}},
+		},
+		{
+			Raw:           []byte{0x1B, 0xCF, 0x33, 0x44, 0x09},
+			Payload:       []byte{0x33, 0x44},
+			Offset:        7,
+			SyncBitErrors: 1,
+			Annotations:   []Annotation{{Name: "trailer", Value: 9}},
+		},
+		{
+			Raw:         []byte{0x1A, 0xCF, 0x55, 0x66, 0x0F},
+			Payload:     []byte{0x55, 0x66},
+			Offset:      12,
+			Inverted:    true,
+			Annotations: []Annotation{{Name: "trailer", Value: 15}},
+		},
+	}
+
+	got := []Frame{}
+	for frm := range frmC {
+		if frm.Timestamp.IsZero() {
+			t.Errorf("frame %d: expected non-zero timestamp", len(got))
+		}
+		frm.Timestamp = time.Time{}
+		got = append(got, *frm)
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected frames: want=%+v got=%+v", want, got)
+	}
+
+	for err := range errC {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestFrameReceiver_Next(t *testing.T) {
+	crc32Adapter, _ := crc.NewCRC32Adapter(crc.CRC32AdapterConfig{
+		Algorithm: crc.CRC32c,
+	})
+
+	cfg := FrameConfig{
+		FrameSyncMarker: []byte{0xFF},
+		FrameSize:       6,
+		Adapters: []Adapter{
+			crc32Adapter,
+		},
+	}
+
+	input := []byte{
+		0xFF, 0x11, 0x22, 0x1C, 0x80, 0xE0, 0x0D, // good frame
+		0xFF, 0x33, 0x44, 0x03, 0x29, 0x99, 0x99, // bad frame (checksum)
+		0xFF, 0x33, 0x44, 0x03, 0x29, 0x47, 0x6b, // good frame
+	}
+
+	fr, err := NewFrameReceiver(cfg, bytes.NewBuffer(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+
+	msgs := [][]byte{}
+	errs := []error{}
+	for {
+		frm, err := fr.Next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		msgs = append(msgs, frm.Payload)
+	}
+
+	wantMessages := [][]byte{
+		[]byte{0x11, 0x22},
+		[]byte{0x33, 0x44},
+	}
+	if !reflect.DeepEqual(wantMessages, msgs) {
+		t.Errorf("unexpected messages: want=% x got=% x", wantMessages, msgs)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected 1 error, got %d", len(errs))
+	}
+
+	// depleted source continues to report EOF
+	if _, err := fr.Next(ctx); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+type observedFrame struct {
+	dir     FrameDirection
+	raw     []byte
+	payload []byte
+	offset  int64
+}
+
+type recordingObserver struct {
+	frames []observedFrame
+}
+
+func (o *recordingObserver) ObserveFrame(dir FrameDirection, frm *Frame) {
+	o.frames = append(o.frames, observedFrame{
+		dir:     dir,
+		raw:     append([]byte{}, frm.Raw...),
+		payload: append([]byte{}, frm.Payload...),
+		offset:  frm.Offset,
+	})
+}
+
+func TestFrameConfig_Observer(t *testing.T) {
+	crc32Adapter, _ := crc.NewCRC32Adapter(crc.CRC32AdapterConfig{
+		Algorithm: crc.CRC32c,
+	})
+
+	obs := &recordingObserver{}
+	cfg := FrameConfig{
+		FrameSyncMarker: []byte{0xFF},
+		FrameSize:       6,
+		Adapters: []Adapter{
+			crc32Adapter,
+		},
+		Observer: obs,
+	}
+
+	var buf bytes.Buffer
+	fs, err := NewFrameSender(cfg, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, msg := range [][]byte{{0x11, 0x22}, {0x33, 0x44}} {
+		if err := fs.Send(msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// failed frames are not observed
+	buf.Write([]byte{0xFF, 0x33, 0x44, 0x03, 0x29, 0x99, 0x99})
+
+	fr, err := NewFrameReceiver(cfg, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for {
+		if _, err := fr.Next(context.Background()); err == io.EOF {
+			break
+		}
+	}
+
+	frame1 := []byte{0xFF, 0x11, 0x22, 0x1C, 0x80, 0xE0, 0x0D}
+	frame2 := []byte{0xFF, 0x33, 0x44, 0x03, 0x29, 0x47, 0x6b}
+	want := []observedFrame{
+		{FRAME_DIRECTION_SENT, frame1, []byte{0x11, 0x22}, 0},
+		{FRAME_DIRECTION_SENT, frame2, []byte{0x33, 0x44}, 7},
+		{FRAME_DIRECTION_RECEIVED, frame1, []byte{0x11, 0x22}, 0},
+		{FRAME_DIRECTION_RECEIVED, frame2, []byte{0x33, 0x44}, 7},
+	}
+	if !reflect.DeepEqual(want, obs.frames) {
+		t.Errorf("unexpected result: want=%v got=%v", want, obs.frames)
+	}
+}
+
+// Prefixes each frame with a single length byte. A zero length is
+// reported as a malformed frame.
+type lengthPrefixCodec struct{}
+
+func (lengthPrefixCodec) NewEncoder() FrameEncoder {
+	return lengthPrefixEncoder{}
+}
+
+func (lengthPrefixCodec) NewDecoder(src io.Reader) FrameDecoder {
+	return &lengthPrefixDecoder{src: src}
+}
+
+type lengthPrefixEncoder struct{}
+
+func (lengthPrefixEncoder) AppendFrame(dst, frm []byte) ([]byte, error) {
+	return append(append(dst, byte(len(frm))), frm...), nil
+}
+
+type lengthPrefixDecoder struct {
+	src io.Reader
+	buf [256]byte
+}
+
+func (d *lengthPrefixDecoder) ReadFrame() ([]byte, error) {
+	if _, err := io.ReadFull(d.src, d.buf[:1]); err != nil {
+		return nil, err
+	}
+	n := int(d.buf[0])
+	if n == 0 {
+		return nil, fmt.Errorf("%w: empty frame", ErrDecodeFailure)
+	}
+	if _, err := io.ReadFull(d.src, d.buf[:n]); err != nil {
+		return nil, err
+	}
+	return d.buf[:n], nil
+}
+
+func TestFrameConfig_Codec(t *testing.T) {
+	crc16Adapter, _ := crc.NewCRC16Adapter(crc.CRC16AdapterConfig{
+		Algorithm: crc16.CRC16_CCITT_FALSE,
+	})
+
+	cfg := FrameConfig{
+		Codec:     lengthPrefixCodec{},
+		FrameSize: 6,
+		Adapters: []Adapter{
+			crc16Adapter,
+		},
+	}
+
+	var buf bytes.Buffer
+	fs, err := NewFrameSender(cfg, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgs := [][]byte{{0x01}, {0x02, 0x03, 0x04, 0x05}}
+	for _, msg := range msgs {
+		if err := fs.Send(msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// malformed, oversized and corrupted frames follow
+	buf.Write([]byte{0x00})
+	buf.Write([]byte{0x07, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07})
+	buf.Write([]byte{0x03, 0x01, 0x02, 0x03})
+
+	fr, err := NewFrameReceiver(cfg, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got [][]byte
+	var errs int
+	for {
+		frm, err := fr.Next(context.Background())
+		if err == io.EOF {
+			break
+		} else if errors.Is(err, ErrDecodeFailure) {
+			errs++
+			continue
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, append([]byte{}, frm.Payload...))
+	}
+
+	if !reflect.DeepEqual(msgs, got) {
+		t.Errorf("unexpected result: want=% x got=% x", msgs, got)
+	}
+	if errs != 3 {
+		t.Errorf("unexpected decode failures: want=3 got=%d", errs)
+	}
+
+	stats := fr.Stats()
+	if stats.CodecErrors != 1 || stats.FrameLengthErrors != 1 || stats.AdapterErrors[0] != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+// Reports the length of each frame read as an annotation.
+type annotatingLengthPrefixCodec struct {
+	lengthPrefixCodec
+}
+
+func (annotatingLengthPrefixCodec) NewDecoder(src io.Reader) FrameDecoder {
+	return &annotatingLengthPrefixDecoder{lengthPrefixDecoder{src: src}}
+}
+
+type annotatingLengthPrefixDecoder struct {
+	lengthPrefixDecoder
+}
+
+func (d *annotatingLengthPrefixDecoder) ReadFrameAnnotated() ([]byte, []Annotation, error) {
+	frm, err := d.ReadFrame()
+	if err != nil {
+		return nil, nil, err
+	}
+	return frm, []Annotation{{Name: "length", Value: len(frm)}}, nil
+}
+
+func TestFrameConfig_AnnotatingCodec(t *testing.T) {
+	cfg := FrameConfig{
+		Codec:     annotatingLengthPrefixCodec{},
+		FrameSize: 4,
+		Adapters: []Adapter{
+			&trailerAdapter{},
+		},
+	}
+
+	var buf bytes.Buffer
+	fs, err := NewFrameSender(cfg, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.Send([]byte{0x01, 0x02}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fr, err := NewFrameReceiver(cfg, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	frm, err := fr.Next(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// codec annotations precede those of the adapters
+	want := []Annotation{
+		{Name: "length", Value: 3},
+		{Name: "trailer", Value: 0},
+	}
+	if !reflect.DeepEqual(want, frm.Annotations) {
+		t.Errorf("unexpected annotations: want=%+v got=%+v", want, frm.Annotations)
+	}
+}
+
+func TestFrameReceiver_NextCancelled(t *testing.T) {
+	cfg := FrameConfig{
+		FrameSyncMarker: []byte{0xFF},
+		FrameSize:       1,
+	}
+
+	fr, err := NewFrameReceiver(cfg, bytes.NewBuffer([]byte{0xFF, 0x11}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := fr.Next(ctx); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestFrameReceiver_Close(t *testing.T) {
+	cfg := FrameConfig{
+		FrameSyncMarker: []byte{0xFF},
+		FrameSize:       1,
+	}
+
+	input := bytes.Repeat([]byte{0xFF, 0x11}, 10)
+
+	fr, err := NewFrameReceiver(cfg, bytes.NewBuffer(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+
+	if _, err := fr.Next(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Receive blocks trying to deliver a message until Close is called
+	msgC := make(chan []byte)
+	stopped := make(chan struct{})
+	go func() {
+		fr.Receive(ctx, msgC, nil)
+		close(stopped)
+	}()
+
+	<-msgC
+
+	if err := fr.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("Receive failed to stop after Close")
+	}
+
+	if _, err := fr.Next(ctx); err != ErrReceiverClosed {
+		t.Errorf("expected ErrReceiverClosed, got %v", err)
+	}
+
+	// repeated Close is harmless
+	if err := fr.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFrameSender_ZeroAlloc(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations not measurable with race detector")
+	}
+
+	cfg, _ := makeSRS4Stream(t, 0, 0)
+	fs, err := NewFrameSender(cfg, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := make([]byte, 200)
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := fs.Send(msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("unexpected allocations per frame: %v", allocs)
+	}
+}
+
+func TestFrameSender_SyncMarkerNotModified(t *testing.T) {
+	// spare capacity must not be written to while encoding frames
+	syncMarker := make([]byte, 4, 16)
+	copy(syncMarker, satlab.SATLAB_ASM)
+
+	cfg := FrameConfig{
+		FrameSyncMarker: syncMarker,
+		FrameSize:       3,
+	}
+
+	fs, err := NewFrameSender(cfg, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := fs.Send([]byte{0x01, 0x02, 0x03}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if spare := syncMarker[4:7]; !reflect.DeepEqual(spare, make([]byte, 3)) {
+		t.Errorf("unexpected write to sync marker: % x", spare)
+	}
+}
+
+func TestFrameReceiver_NextIntoZeroAlloc(t *testing.T) {
+	cfg, stream := makeSRS4Stream(t, 8, 16)
+
+	fr, err := NewFrameReceiver(cfg, &repeatReader{data: stream})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var frm Frame
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := fr.NextInto(context.Background(), &frm); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("unexpected allocations per frame: %v", allocs)
+	}
+
+	if len(frm.Payload) != 200 {
+		t.Errorf("unexpected payload length: want=200 got=%d", len(frm.Payload))
+	}
+}
+
+func BenchmarkFrameSender_SRS4(b *testing.B) {
+	cfg, _ := makeSRS4Stream(b, 0, 0)
+	fs, err := NewFrameSender(cfg, io.Discard)
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := make([]byte, 200)
+
+	b.SetBytes(int64(len(cfg.FrameSyncMarker) + cfg.FrameSize))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := fs.Send(msg); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
@@ -16,18 +16,59 @@ package satcom
 
 import (
 	"bytes"
+	"errors"
 	"io"
+	"math/bits"
 )
 
+// Describes the sync marker match used to lock on to a frame.
+type SyncLock struct {
+	// Number of bits in the received sync marker that differ
+	// from the expected value.
+	BitErrors int
+
+	// Indicates the bitwise complement of the sync marker was
+	// located, meaning the frame data must also be inverted.
+	Inverted bool
+
+	// Number of bytes discarded ahead of the sync marker.
+	Skipped int
+
+	// Position of the sync marker within the stream, in bytes.
+	Offset int64
+}
+
+// Common interface for byte- and bit-oriented frame readers.
+type syncReader interface {
+	// Block until a sync marker is located at the head of the stream.
+	Seek() (SyncLock, error)
+
+	// Return the leading n bytes of the stream without consuming them.
+	// The returned slice is only valid until the next operation.
+	Peek(n int) ([]byte, error)
+
+	// Consume n bytes, which must have already been peeked at.
+	Discard(n int) error
+
+	// Consume the smallest unit of data at the head of the stream (a
+	// single byte, or bit for bitstreams), such that a subsequent Seek
+	// resumes the search for a sync marker from the very next position.
+	Advance() error
+}
+
 func NewFrameReader(src io.Reader, syncMarker []byte, frameBufferSize int) *frameReader {
 	return &frameReader{
 		source:     src,
 		syncMarker: syncMarker,
-		readBuffer: make([]byte, frameBufferSize),
+		size:       frameBufferSize,
+		readBuffer: make([]byte, 2*frameBufferSize),
 	}
-
 }
 
+// Number of consecutive empty reads tolerated from the source before
+// giving up, matching the behavior of bufio.
+const maxConsecutiveEmptyReads = 100
+
 // Reads frames from a source io.Reader. The start of a frame is identified by
 // first seeking through the source reader for the occurence of a sync marker.
 // The frame end is identified by a subsequent sync marker, reaching the max
@@ -36,8 +77,37 @@ type frameReader struct {
 	source     io.Reader
 	syncMarker []byte
 
+	// Maximum number of bit errors tolerated when matching the sync marker
+	maxBitErrors int
+
+	// Bitwise complement of the sync marker, set only if inverted
+	// sync markers should be located.
+	invertedMarker []byte
+
+	// Ring buffer of the given size. Every byte is stored twice, at
+	// index i and i+size, so buffered data is always available as a
+	// contiguous slice without moving memory on discard.
 	readBuffer []byte
-	cursor     int
+	size       int
+
+	// Index of the first buffered byte, always less than size.
+	start int
+
+	// Number of buffered bytes.
+	cursor int
+
+	// Number of leading buffered bytes already ruled out as the start
+	// of a sync marker, so they are not searched again.
+	searched int
+
+	// Number of bytes discarded from the head of the buffer since
+	// reading began, used to track position within the stream.
+	offset int64
+}
+
+// Return all buffered data as a contiguous slice.
+func (c *frameReader) buffered() []byte {
+	return c.readBuffer[c.start : c.start+c.cursor]
 }
 
 // Read through enough data from the source to completely fill the provided byte slice.
@@ -50,23 +120,57 @@ func (c *frameReader) Read(dst []byte) (int, error) {
 		return 0, err
 	}
 
-	copy(dst, c.readBuffer[:dstN])
+	copy(dst, c.buffered())
 	c.seekToIndex(dstN)
 
 	return dstN, nil
 }
 
+// Read through enough data from the source to return the leading n bytes,
+// without consuming them. The returned slice is only valid until the next
+// operation on the frameReader.
+func (c *frameReader) Peek(n int) ([]byte, error) {
+	if err := c.fillReadBuffer(n); err != nil {
+		return nil, err
+	}
+
+	return c.buffered()[:n], nil
+}
+
+// Consume n bytes that have already been buffered (i.e. by Peek).
+func (c *frameReader) Discard(n int) error {
+	if n > c.cursor {
+		return errors.New("discard exceeds buffered data")
+	}
+
+	c.seekToIndex(n)
+
+	return nil
+}
+
+// Consume the first buffered byte.
+func (c *frameReader) Advance() error {
+	return c.Discard(1)
+}
+
 // Continue reading from source until a sync marker is identified. This will block
 // until the a sync marker is found or the underlying source is depleted.
-func (c *frameReader) Seek() error {
+func (c *frameReader) Seek() (SyncLock, error) {
+	syncN := len(c.syncMarker)
+
+	var skipped int
 	for {
 		// fill read buffer with at least enough data to check for the sync marker
-		if err := c.fillReadBuffer(len(c.syncMarker)); err != nil {
-			return err
+		if err := c.fillReadBuffer(syncN); err != nil {
+			return SyncLock{Skipped: skipped}, err
 		}
 
-		// now, check the entire readBuffer (which may be much larger than the sync marker)
-		idx := bytes.Index(c.readBuffer[:c.cursor], c.syncMarker)
+		// now, check the unsearched portion of the buffer (which may be
+		// much larger than the sync marker)
+		idx, lock, err := c.findSyncMarker()
+		if err != nil {
+			return SyncLock{Skipped: skipped}, err
+		}
 
 		// sync marker found
 		if idx >= 0 {
@@ -74,32 +178,178 @@ func (c *frameReader) Seek() error {
 			if idx >= 1 {
 				c.seekToIndex(idx)
 			}
-			return nil
+			lock.Skipped = skipped + idx
+			lock.Offset = c.offset
+			return lock, nil
 		}
 
 		// no sync marker identified, so we discard all irrelevant data and repeat
-		c.seekToIndex(c.cursor - len(c.syncMarker) + 1)
+		n := c.cursor - syncN + 1
+		c.seekToIndex(n)
+		skipped += n
+	}
+}
+
+// Enable detection of the bitwise complement of the sync marker, as caused by
+// phase ambiguity in BPSK/GMSK demodulators. The provided mask identifies the
+// significant bits of each byte in the stream.
+func (c *frameReader) detectInverted(mask byte) {
+	c.invertedMarker = make([]byte, len(c.syncMarker))
+	for i, b := range c.syncMarker {
+		c.invertedMarker[i] = b ^ mask
+	}
+}
+
+// Locate the first sync marker in the read buffer, returning its index and details
+// of the match. If bit errors are tolerated, each candidate overlapping the best
+// match found so far is also considered, preferring the closest match. This may
+// read more data from the source. An index of -1 is returned if no match is
+// found. Positions ruled out by a previous search are not considered again.
+func (c *frameReader) findSyncMarker() (int, SyncLock, error) {
+	syncN := len(c.syncMarker)
+
+	idx, lock, err := c.searchFrom(c.searched)
+	if err != nil {
+		return -1, SyncLock{}, err
+	}
+	if idx >= 0 {
+		c.searched = idx
+	} else if c.cursor >= syncN {
+		c.searched = c.cursor - syncN + 1
+	}
+
+	return idx, lock, nil
+}
+
+func (c *frameReader) searchFrom(from int) (int, SyncLock, error) {
+	syncN := len(c.syncMarker)
+	buf := c.buffered()
+
+	if from+syncN > len(buf) {
+		return -1, SyncLock{}, nil
+	}
+
+	if c.maxBitErrors == 0 {
+		idx := bytes.Index(buf[from:], c.syncMarker)
+		if idx >= 0 {
+			idx += from
+		}
+
+		// an inverted marker is only relevant if it precedes a normal marker
+		if c.invertedMarker != nil {
+			end := len(buf)
+			if idx >= 0 {
+				end = idx + syncN - 1
+			}
+			if iidx := bytes.Index(buf[from:end], c.invertedMarker); iidx >= 0 {
+				return from + iidx, SyncLock{Inverted: true}, nil
+			}
+		}
+
+		return idx, SyncLock{}, nil
+	}
+
+	for idx := from; idx+syncN <= c.cursor; idx++ {
+		lock, ok := c.matchAt(idx, c.maxBitErrors)
+		if !ok {
+			continue
+		}
+
+		// Each closer match extends the range of candidates, buffering more
+		// data as needed. Reaching the end of the source only limits the
+		// candidates that can be considered.
+		best := idx
+		for i := idx + 1; i < best+syncN && i+syncN <= c.size && lock.BitErrors > 0; i++ {
+			if err := c.fillReadBuffer(i + syncN); err == io.EOF {
+				break
+			} else if err != nil {
+				return -1, SyncLock{}, err
+			}
+
+			if l, ok := c.matchAt(i, lock.BitErrors-1); ok {
+				best, lock = i, l
+			}
+		}
+
+		return best, lock, nil
 	}
+
+	return -1, SyncLock{}, nil
 }
 
+// Compare the sync marker (and inverted sync marker, if enabled) to the buffered
+// data at the given index, returning the closest match if it is within limit.
+func (c *frameReader) matchAt(idx int, limit int) (SyncLock, bool) {
+	buf := c.buffered()
+	lock := SyncLock{
+		BitErrors: bitErrors(c.syncMarker, buf[idx:], limit),
+	}
+
+	if c.invertedMarker != nil && lock.BitErrors > 0 {
+		invLimit := limit
+		if lock.BitErrors <= limit {
+			invLimit = lock.BitErrors - 1
+		}
+		if n := bitErrors(c.invertedMarker, buf[idx:], invLimit); n <= invLimit {
+			lock = SyncLock{BitErrors: n, Inverted: true}
+		}
+	}
+
+	return lock, lock.BitErrors <= limit
+}
+
+// Count the bits that differ between the marker and the leading bytes of the
+// provided data. Counting stops early once the limit is exceeded.
+func bitErrors(marker []byte, data []byte, limit int) int {
+	var n int
+	for i, b := range marker {
+		n += bits.OnesCount8(b ^ data[i])
+		if n > limit {
+			break
+		}
+	}
+	return n
+}
+
+// Read from the source until at least target bytes are buffered.
 func (c *frameReader) fillReadBuffer(target int) error {
+	if target > c.size {
+		return errors.New("read exceeds buffer size")
+	}
+
+	var empty int
 	for {
 		// enough data has already been buffered
 		if c.cursor >= target {
 			return nil
 		}
 
+		// read into the free space following the buffered data, which
+		// may wrap around to the start of the ring
+		w := (c.start + c.cursor) % c.size
+		end := w + c.size - c.cursor
+		if end > c.size {
+			end = c.size
+		}
+
 		// read some more data, even if it is not enough to top up
 		// the buffer to the target level (will repeat)
-		n, err := c.source.Read(c.readBuffer[c.cursor:])
+		n, err := c.source.Read(c.readBuffer[w:end])
 		if n == 0 {
 			if err != nil {
 				return err
 			}
 
-			//NOTE(bcwaldon): not sure how to handle this case yet
-			panic("n==0")
+			empty++
+			if empty >= maxConsecutiveEmptyReads {
+				return io.ErrNoProgress
+			}
+			continue
 		}
+		empty = 0
+
+		// mirror the new data into the second half of the ring
+		copy(c.readBuffer[w+c.size:], c.readBuffer[w:w+n])
 
 		c.cursor = c.cursor + n
 	}
@@ -107,6 +357,12 @@ func (c *frameReader) fillReadBuffer(target int) error {
 
 // Discard N leading bytes from buffer.
 func (c *frameReader) seekToIndex(n int) {
-	copy(c.readBuffer, c.readBuffer[n:])
+	c.start = (c.start + n) % c.size
 	c.cursor = c.cursor - n
+	c.offset += int64(n)
+
+	c.searched -= n
+	if c.searched < 0 {
+		c.searched = 0
+	}
 }
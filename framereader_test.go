@@ -16,8 +16,15 @@ package satcom
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
 	"reflect"
 	"testing"
+	"testing/iotest"
+
+	"github.com/antaris-inc/go-satcom/satlab"
 )
 
 func TestFrameReaderSingleFrame(t *testing.T) {
@@ -29,7 +36,7 @@ func TestFrameReaderSingleFrame(t *testing.T) {
 
 	rd := NewFrameReader(buf, syncMarker, 128)
 
-	if err := rd.Seek(); err != nil {
+	if _, err := rd.Seek(); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -61,7 +68,7 @@ func TestFrameReaderDynamicLengthFrame(t *testing.T) {
 
 	rd := NewFrameReader(buf, syncMarker, 128)
 
-	if err := rd.Seek(); err != nil {
+	if _, err := rd.Seek(); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -105,7 +112,7 @@ func TestFrameReaderLargeSeek(t *testing.T) {
 
 	rd := NewFrameReader(buf, syncMarker, 128)
 
-	if err := rd.Seek(); err != nil {
+	if _, err := rd.Seek(); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -135,7 +142,7 @@ func TestFrameReaderPartialSyncMarker(t *testing.T) {
 
 	rd := NewFrameReader(buf, syncMarker, 128)
 
-	if err := rd.Seek(); err != nil {
+	if _, err := rd.Seek(); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -151,3 +158,398 @@ func TestFrameReaderPartialSyncMarker(t *testing.T) {
 		t.Fatalf("unexpected result: want=% x, got=% x", want, got)
 	}
 }
+
+func TestFrameReaderSyncMarkerBitErrors(t *testing.T) {
+	syncMarker := []byte{0x1A, 0xCF, 0xFC, 0x1D}
+
+	tests := []struct {
+		input         []byte
+		maxBitErrors  int
+		want          []byte
+		wantBitErrors int
+	}{
+		// exact match
+		{
+			input:         []byte{0x00, 0x1A, 0xCF, 0xFC, 0x1D, 0x11},
+			maxBitErrors:  3,
+			want:          []byte{0x1A, 0xCF, 0xFC, 0x1D, 0x11},
+			wantBitErrors: 0,
+		},
+
+		// single bit error
+		{
+			input:         []byte{0x00, 0x1A, 0xCF, 0xFD, 0x1D, 0x11},
+			maxBitErrors:  3,
+			want:          []byte{0x1A, 0xCF, 0xFD, 0x1D, 0x11},
+			wantBitErrors: 1,
+		},
+
+		// three bit errors spread across marker
+		{
+			input:         []byte{0x1B, 0xCF, 0xFC, 0x1E, 0x11, 0x80},
+			maxBitErrors:  3,
+			want:          []byte{0x1B, 0xCF, 0xFC, 0x1E, 0x11},
+			wantBitErrors: 3,
+		},
+
+		// overlapping candidate with fewer errors is preferred
+		{
+			input:         []byte{0x1A, 0x1A, 0xCF, 0xFC, 0x1D, 0x11},
+			maxBitErrors:  12,
+			want:          []byte{0x1A, 0xCF, 0xFC, 0x1D, 0x11},
+			wantBitErrors: 0,
+		},
+
+		// closer candidates overlapping each other, where the best does
+		// not overlap the first acceptable match
+		{
+			input:         []byte{0x1A, 0xCE, 0x1A, 0xCF, 0x1A, 0xCF, 0xFC, 0x1D, 0x11},
+			maxBitErrors:  12,
+			want:          []byte{0x1A, 0xCF, 0xFC, 0x1D, 0x11},
+			wantBitErrors: 0,
+		},
+	}
+
+	for ti, tt := range tests {
+		rd := NewFrameReader(bytes.NewBuffer(tt.input), syncMarker, 128)
+		rd.maxBitErrors = tt.maxBitErrors
+
+		lock, err := rd.Seek()
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", ti, err)
+			continue
+		}
+		if lock.BitErrors != tt.wantBitErrors {
+			t.Errorf("case %d: unexpected bit errors: want=%d got=%d", ti, tt.wantBitErrors, lock.BitErrors)
+		}
+
+		got := make([]byte, len(tt.want))
+		if _, err := rd.Read(got); err != nil {
+			t.Errorf("case %d: unexpected error: %v", ti, err)
+		} else if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: unexpected result: want=% x, got=% x", ti, tt.want, got)
+		}
+	}
+}
+
+func TestFrameReaderSyncMarkerTooManyBitErrors(t *testing.T) {
+	syncMarker := []byte{0x1A, 0xCF, 0xFC, 0x1D}
+
+	// four bit errors in the marker
+	buf := bytes.NewBuffer([]byte{0x1B, 0xCE, 0xFD, 0x1C, 0x11, 0x22})
+
+	rd := NewFrameReader(buf, syncMarker, 128)
+	rd.maxBitErrors = 3
+
+	if _, err := rd.Seek(); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestFrameReaderInvertedSyncMarker(t *testing.T) {
+	syncMarker := []byte{0x1A, 0xCF, 0xFC, 0x1D}
+
+	tests := []struct {
+		input        []byte
+		maxBitErrors int
+		want         []byte
+		wantLock     SyncLock
+	}{
+		// inverted marker
+		{
+			input:    []byte{0x00, 0xE5, 0x30, 0x03, 0xE2, 0x11},
+			want:     []byte{0xE5, 0x30, 0x03, 0xE2, 0x11},
+			wantLock: SyncLock{Inverted: true, Skipped: 1, Offset: 1},
+		},
+
+		// normal marker following an inverted marker
+		{
+			input:    []byte{0xE5, 0x30, 0x03, 0xE2, 0x1A, 0xCF, 0xFC, 0x1D},
+			want:     []byte{0xE5, 0x30, 0x03, 0xE2},
+			wantLock: SyncLock{Inverted: true},
+		},
+
+		// inverted marker following a normal marker
+		{
+			input:    []byte{0x1A, 0xCF, 0xFC, 0x1D, 0xE5, 0x30, 0x03, 0xE2},
+			want:     []byte{0x1A, 0xCF, 0xFC, 0x1D},
+			wantLock: SyncLock{},
+		},
+
+		// inverted marker with bit errors
+		{
+			input:        []byte{0x00, 0xE5, 0x31, 0x03, 0xE3, 0x11},
+			maxBitErrors: 3,
+			want:         []byte{0xE5, 0x31, 0x03, 0xE3, 0x11},
+			wantLock:     SyncLock{BitErrors: 2, Inverted: true, Skipped: 1, Offset: 1},
+		},
+	}
+
+	for ti, tt := range tests {
+		rd := NewFrameReader(bytes.NewBuffer(tt.input), syncMarker, 128)
+		rd.maxBitErrors = tt.maxBitErrors
+		rd.detectInverted(0xFF)
+
+		lock, err := rd.Seek()
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", ti, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.wantLock, lock) {
+			t.Errorf("case %d: unexpected lock: want=%+v got=%+v", ti, tt.wantLock, lock)
+		}
+
+		got := make([]byte, len(tt.want))
+		if _, err := rd.Read(got); err != nil {
+			t.Errorf("case %d: unexpected error: %v", ti, err)
+		} else if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: unexpected result: want=% x, got=% x", ti, tt.want, got)
+		}
+	}
+}
+
+func TestFrameReaderSkipped(t *testing.T) {
+	syncMarker := []byte{0x01, 0x02}
+
+	buf := new(bytes.Buffer)
+	for i := 0; i < 100; i++ {
+		buf.Write([]byte{0x03})
+	}
+	buf.Write(syncMarker)
+	buf.Write([]byte{0x03, 0x03})
+
+	rd := NewFrameReader(buf, syncMarker, 16)
+
+	lock, err := rd.Seek()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lock.Skipped != 100 {
+		t.Fatalf("expected 100 bytes skipped, got %d", lock.Skipped)
+	}
+
+	if _, err := rd.Read(make([]byte, 2)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lock, err = rd.Seek()
+	if err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+	if lock.Skipped != 1 {
+		t.Fatalf("expected 1 byte skipped, got %d", lock.Skipped)
+	}
+}
+
+func TestFrameReaderPeekDiscard(t *testing.T) {
+	input := []byte{0x01, 0x1A, 0xCF, 0xFC, 0x1D, 0x02, 0x1A, 0xCF, 0xFC, 0x1D, 0x03}
+	syncMarker := []byte{0x1A, 0xCF, 0xFC, 0x1D}
+	fr := NewFrameReader(bytes.NewBuffer(input), syncMarker, 32)
+
+	if _, err := fr.Seek(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := fr.Peek(6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []byte{0x1A, 0xCF, 0xFC, 0x1D, 0x02, 0x1A}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=% x got=% x", want, got)
+	}
+
+	// advancing past the start of the marker must find the next one
+	if err := fr.Advance(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lock, err := fr.Seek()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lock.Offset != 6 {
+		t.Errorf("unexpected offset: want=6 got=%d", lock.Offset)
+	}
+
+	if _, err := fr.Peek(5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fr.Discard(5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fr.Discard(1); err == nil {
+		t.Errorf("expected error discarding unbuffered data")
+	}
+	if _, err := fr.Peek(33); err == nil {
+		t.Errorf("expected error peeking beyond buffer size")
+	}
+}
+
+// Returns at most n bytes per read from the underlying reader.
+type chunkReader struct {
+	src io.Reader
+	rnd *rand.Rand
+	max int
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if n := 1 + r.rnd.Intn(r.max); len(p) > n {
+		p = p[:n]
+	}
+	return r.src.Read(p)
+}
+
+func TestFrameReaderRingBuffer(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	input := make([]byte, 4096)
+	rnd.Read(input)
+
+	src := &chunkReader{src: bytes.NewReader(input), rnd: rnd, max: 13}
+	fr := NewFrameReader(src, []byte{0x00}, 17)
+
+	var pos int
+	for pos < len(input) {
+		n := 1 + rnd.Intn(17)
+		if pos+n > len(input) {
+			n = len(input) - pos
+		}
+
+		got, err := fr.Peek(n)
+		if err != nil {
+			t.Fatalf("pos %d: unexpected error: %v", pos, err)
+		}
+		if want := input[pos : pos+n]; !bytes.Equal(want, got) {
+			t.Fatalf("pos %d: unexpected result: want=% x got=% x", pos, want, got)
+		}
+
+		d := 1 + rnd.Intn(n)
+		if err := fr.Discard(d); err != nil {
+			t.Fatalf("pos %d: unexpected error: %v", pos, err)
+		}
+		pos += d
+	}
+
+	if fr.offset != int64(len(input)) {
+		t.Errorf("unexpected offset: want=%d got=%d", len(input), fr.offset)
+	}
+}
+
+type emptyReader struct{}
+
+func (emptyReader) Read(p []byte) (int, error) {
+	return 0, nil
+}
+
+// Confirms a read error encountered while comparing overlapping
+// candidates is returned rather than discarded.
+func TestFrameReaderSyncMarkerReadError(t *testing.T) {
+	syncMarker := []byte{0x1A, 0xCF, 0xFC, 0x1D}
+	errRead := errors.New("read failed")
+
+	// single bit error, so later overlapping candidates must be read
+	src := io.MultiReader(bytes.NewReader([]byte{0x1A, 0xCF, 0xFD, 0x1D}), iotest.ErrReader(errRead))
+
+	rd := NewFrameReader(src, syncMarker, 128)
+	rd.maxBitErrors = 3
+
+	if _, err := rd.Seek(); err != errRead {
+		t.Fatalf("unexpected error: want=%v got=%v", errRead, err)
+	}
+}
+
+func TestFrameReaderNoProgress(t *testing.T) {
+	fr := NewFrameReader(emptyReader{}, []byte{0x01, 0x02}, 16)
+	if _, err := fr.Seek(); err != io.ErrNoProgress {
+		t.Errorf("unexpected error: want=%v got=%v", io.ErrNoProgress, err)
+	}
+}
+
+// Repeats the provided data indefinitely.
+type repeatReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	n := copy(p, r.data[r.pos:])
+	r.pos = (r.pos + n) % len(r.data)
+	return n, nil
+}
+
+// Generate a stream of Satlab SRS4 frames (including sync markers), with
+// the given number of random bytes of noise ahead of each frame.
+func makeSRS4Stream(tb testing.TB, frames, noise int) (FrameConfig, []byte) {
+	cfg := FrameConfig{
+		FrameSyncMarker: satlab.SATLAB_ASM,
+		FrameSize:       223,
+		Adapters:        makeSpaceframeStack(217),
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	buf := bytes.NewBuffer(nil)
+	fs, err := NewFrameSender(cfg, buf)
+	if err != nil {
+		tb.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := make([]byte, 200)
+	for i := 0; i < frames; i++ {
+		junk := make([]byte, noise)
+		rnd.Read(junk)
+		buf.Write(junk)
+
+		rnd.Read(msg)
+		if err := fs.Send(msg); err != nil {
+			tb.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	return cfg, buf.Bytes()
+}
+
+func benchmarkFrameReceiver(b *testing.B, noise int, modify func(*FrameConfig)) {
+	cfg, stream := makeSRS4Stream(b, 64, noise)
+	if modify != nil {
+		modify(&cfg)
+	}
+
+	fr, err := NewFrameReceiver(cfg, &repeatReader{data: stream})
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+
+	// throughput is measured against the raw stream, including noise
+	b.SetBytes(int64(len(stream) / 64))
+	b.ResetTimer()
+
+	b.ReportAllocs()
+
+	var frm Frame
+	for i := 0; i < b.N; i++ {
+		if err := fr.NextInto(context.Background(), &frm); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func BenchmarkFrameReceiver_SRS4(b *testing.B) {
+	benchmarkFrameReceiver(b, 0, nil)
+}
+
+func BenchmarkFrameReceiver_SRS4Noisy(b *testing.B) {
+	benchmarkFrameReceiver(b, 512, nil)
+}
+
+func BenchmarkFrameReceiver_SRS4BitErrors(b *testing.B) {
+	benchmarkFrameReceiver(b, 512, func(cfg *FrameConfig) {
+		cfg.SyncMarkerMaxBitErrors = 3
+		cfg.DetectInvertedSyncMarker = true
+	})
+}
+
+func BenchmarkFrameReceiver_SRS4PackedBits(b *testing.B) {
+	benchmarkFrameReceiver(b, 512, func(cfg *FrameConfig) {
+		cfg.StreamFormat = STREAM_FORMAT_PACKED_BITS
+	})
+}
@@ -0,0 +1,44 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package fx25
+
+import (
+	"io"
+
+	satcom "github.com/antaris-inc/go-satcom"
+)
+
+// Frames data using FX.25, for use as the Codec of a satcom.FrameConfig.
+// The HDLC FCS and RS check bytes are added and verified by the codec, so
+// are not included in the FrameSize of the FrameConfig. Frames received
+// report the number of corrected symbols in their Annotations.
+type Codec struct {
+	cfg Config
+}
+
+func NewCodec(cfg Config) (*Codec, error) {
+	if err := cfg.Err(); err != nil {
+		return nil, err
+	}
+	return &Codec{cfg: cfg}, nil
+}
+
+func (c *Codec) NewEncoder() satcom.FrameEncoder {
+	return newEncoder(c.cfg)
+}
+
+func (c *Codec) NewDecoder(src io.Reader) satcom.FrameDecoder {
+	return newDecoder(src, c.cfg)
+}
@@ -0,0 +1,116 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package fx25
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"testing"
+
+	satcom "github.com/antaris-inc/go-satcom"
+	"github.com/antaris-inc/go-satcom/ax25"
+	"github.com/antaris-inc/go-satcom/hdlc"
+)
+
+func TestCodec_FrameSenderReceiver(t *testing.T) {
+	codec, err := NewCodec(Config{
+		Bitstream:       hdlc.Config{NRZI: true, PreambleFlags: 2},
+		TagMaxBitErrors: 8,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	src := ax25.Address{Callsign: "N0CALL", SSID: 7}
+	dest := ax25.Address{Callsign: "APRS"}
+	ad, err := ax25.NewUIAdapter(ax25.UIAdapterConfig{
+		Destination: dest,
+		Source:      src,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	frameSize, _ := ad.MessageSize(64)
+	cfg := satcom.FrameConfig{
+		Codec:     codec,
+		FrameSize: frameSize,
+		Adapters:  []satcom.Adapter{ad},
+	}
+
+	var buf bytes.Buffer
+	fs, err := satcom.NewFrameSender(cfg, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgs := [][]byte{
+		[]byte("hello"),
+		bytes.Repeat([]byte{0x55}, 64),
+	}
+	for i, msg := range msgs {
+		if err := fs.Send(msg); err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+	}
+
+	// corrupt a byte within the second codeblock
+	stream := buf.Bytes()
+	stream[len(stream)-20] ^= 0xFF
+
+	fr, err := satcom.NewFrameReceiver(cfg, bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got [][]byte
+	var corrected []interface{}
+	for {
+		frm, err := fr.Next(context.Background())
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, append([]byte{}, frm.Payload...))
+
+		for _, ann := range frm.Annotations {
+			if ann.Name == "rs.corrected_symbols" {
+				corrected = append(corrected, ann.Value)
+			}
+		}
+		if len(frm.Annotations) != 5 {
+			t.Errorf("unexpected annotations: %+v", frm.Annotations)
+		}
+	}
+
+	if !reflect.DeepEqual(msgs, got) {
+		t.Errorf("unexpected result: want=% x got=% x", msgs, got)
+	}
+
+	// with NRZI, inverting 8 levels only corrupts the bits at either end
+	wantCorrected := []interface{}{0, 2}
+	if !reflect.DeepEqual(wantCorrected, corrected) {
+		t.Errorf("unexpected corrected symbols: want=%v got=%v", wantCorrected, corrected)
+	}
+}
+
+func TestNewCodec_Invalid(t *testing.T) {
+	if _, err := NewCodec(Config{CheckBytes: 1}); err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -0,0 +1,171 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package fx25
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	satcom "github.com/antaris-inc/go-satcom"
+	"github.com/antaris-inc/go-satcom/hdlc"
+	"github.com/antaris-inc/go-satcom/internal/bitstream"
+)
+
+// Locates and decodes FX.25 codeblocks within a bitstream. Codeblocks are
+// found by searching for a correlation tag, tolerating up to the configured
+// number of bit errors. Errors within each codeblock are then corrected,
+// and the HDLC frame within it returned once its FCS is verified.
+type Decoder struct {
+	cfg  Config
+	bits *bitstream.Reader
+
+	// The most recent bits received, with the latest in the most
+	// significant bit, and the number of bits held.
+	reg  uint64
+	regN int
+
+	// Set once a correlation tag has been found, while the following
+	// codeblock is collected.
+	tag     Tag
+	tagErrs int
+	block   []byte
+	blockN  int
+
+	// Data bits of the codeblock last decoded, one per byte, and
+	// the decoder used to extract the HDLC frame within.
+	unpacked   []byte
+	unpackedRd bytes.Reader
+	hdlc       *hdlc.Decoder
+
+	anns []satcom.Annotation
+}
+
+func NewDecoder(src io.Reader, cfg Config) (*Decoder, error) {
+	if err := cfg.Err(); err != nil {
+		return nil, err
+	}
+	return newDecoder(src, cfg), nil
+}
+
+// Build a Decoder using a config that has already been validated.
+func newDecoder(src io.Reader, cfg Config) *Decoder {
+	d := Decoder{
+		cfg:  cfg,
+		bits: bitstream.NewReader(src, !cfg.Bitstream.Unpacked, cfg.Bitstream.NRZI),
+		hdlc: mustNewHDLCDecoder(),
+	}
+	return &d
+}
+
+// Read the contents of the next valid frame, with FCS removed. Codeblocks
+// that cannot be corrected or do not hold a valid frame are reported using
+// errors that wrap satcom.ErrDecodeFailure, following which decoding may
+// continue. The returned slice is only valid until the next call. This
+// implements the satcom.FrameDecoder interface.
+func (d *Decoder) ReadFrame() ([]byte, error) {
+	frm, _, err := d.ReadFrameAnnotated()
+	return frm, err
+}
+
+// Read the next frame as ReadFrame does, along with annotations giving
+// the codeblock format ("fx25.tag"), the number of bit errors in its
+// correlation tag ("fx25.tag_bit_errors") and the number of bytes
+// corrected ("rs.corrected_symbols"). This implements the
+// satcom.AnnotatingFrameDecoder interface.
+func (d *Decoder) ReadFrameAnnotated() ([]byte, []satcom.Annotation, error) {
+	for {
+		bit, err := d.bits.ReadBit()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if d.tag == 0 {
+			d.search(bit)
+			continue
+		}
+
+		d.block[d.blockN/8] |= bit << (d.blockN % 8)
+		d.blockN++
+		if d.blockN == len(d.block)*8 {
+			return d.decode()
+		}
+	}
+}
+
+// Add a bit to the search for a correlation tag, preparing to collect
+// the following codeblock once one is found.
+func (d *Decoder) search(bit byte) {
+	d.reg = d.reg>>1 | uint64(bit)<<(TAG_LENGTH_BITS-1)
+	if d.regN < TAG_LENGTH_BITS {
+		d.regN++
+		if d.regN < TAG_LENGTH_BITS {
+			return
+		}
+	}
+
+	tag, errs, ok := matchTag(d.reg, d.cfg.TagMaxBitErrors)
+	if !ok {
+		return
+	}
+
+	d.tag = tag
+	d.tagErrs = errs
+	d.blockN = 0
+	if cap(d.block) < tag.BlockSize() {
+		d.block = make([]byte, tag.BlockSize())
+	}
+	d.block = d.block[:tag.BlockSize()]
+	for i := range d.block {
+		d.block[i] = 0
+	}
+}
+
+// Correct the collected codeblock, and extract the HDLC frame within.
+func (d *Decoder) decode() ([]byte, []satcom.Annotation, error) {
+	tag := d.tag
+	d.tag = 0
+	d.regN = 0
+
+	corrected, err := codes[tag.CheckSize()].Decode(d.block)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v codeblock: %v", satcom.ErrDecodeFailure, tag, err)
+	}
+
+	n := tag.DataSize() * 8
+	if cap(d.unpacked) < n {
+		d.unpacked = make([]byte, n)
+	}
+	d.unpacked = d.unpacked[:n]
+	for i := range d.unpacked {
+		d.unpacked[i] = (d.block[i/8] >> (i % 8)) & 1
+	}
+
+	d.unpackedRd.Reset(d.unpacked)
+	d.hdlc.Reset(&d.unpackedRd)
+	frm, err := d.hdlc.ReadFrame()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("%w: %v codeblock holds no frame", satcom.ErrDecodeFailure, tag)
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	d.anns = append(d.anns[:0],
+		satcom.Annotation{Name: "fx25.tag", Value: tag},
+		satcom.Annotation{Name: "fx25.tag_bit_errors", Value: d.tagErrs},
+		satcom.Annotation{Name: "rs.corrected_symbols", Value: corrected},
+	)
+	return frm, d.anns, nil
+}
@@ -0,0 +1,179 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package fx25
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	satcom "github.com/antaris-inc/go-satcom"
+	"github.com/antaris-inc/go-satcom/hdlc"
+)
+
+func TestDecoder_ReadFrameAnnotated(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	tests := []struct {
+		cfg Config
+		frm []byte
+		tag Tag
+
+		// bit errors added to the tag, and byte errors to the codeblock
+		tagErrs   int
+		blockErrs int
+	}{
+		{cfg: Config{}, frm: []byte("hello"), tag: TAG_RS_48_32},
+		{cfg: Config{TagMaxBitErrors: 4}, frm: []byte("hello"), tag: TAG_RS_48_32, tagErrs: 4, blockErrs: 8},
+		{cfg: Config{CheckBytes: 32, TagMaxBitErrors: 1}, frm: bytes.Repeat([]byte{0xFF}, 100), tag: TAG_RS_160_128, tagErrs: 1, blockErrs: 16},
+		{cfg: Config{CheckBytes: 64}, frm: make([]byte, 150), tag: TAG_RS_255_191, blockErrs: 32},
+		{cfg: Config{Tag: TAG_RS_255_239}, frm: []byte("hello"), tag: TAG_RS_255_239, blockErrs: 3},
+	}
+
+	for i, tt := range tests {
+		enc, err := NewEncoder(tt.cfg)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		stream, err := enc.AppendFrame(nil, tt.frm)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+
+		// with packed bits and no NRZI, the tag follows a single
+		// preamble flag and the codeblock follows the tag
+		for _, pos := range rng.Perm(TAG_LENGTH_BITS)[:tt.tagErrs] {
+			stream[1+pos/8] ^= 0x80 >> (pos % 8)
+		}
+		block := stream[9:]
+		for _, pos := range rng.Perm(len(block))[:tt.blockErrs] {
+			block[pos] ^= byte(rng.Intn(255) + 1)
+		}
+
+		dec, err := NewDecoder(bytes.NewReader(stream), tt.cfg)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		got, anns, err := dec.ReadFrameAnnotated()
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.frm, got) {
+			t.Errorf("case %d: unexpected result: want=% x got=% x", i, tt.frm, got)
+		}
+
+		wantAnns := []satcom.Annotation{
+			{Name: "fx25.tag", Value: tt.tag},
+			{Name: "fx25.tag_bit_errors", Value: tt.tagErrs},
+			{Name: "rs.corrected_symbols", Value: tt.blockErrs},
+		}
+		if !reflect.DeepEqual(wantAnns, anns) {
+			t.Errorf("case %d: unexpected annotations: want=%+v got=%+v", i, wantAnns, anns)
+		}
+		if _, err := dec.ReadFrame(); err != io.EOF {
+			t.Errorf("case %d: expected EOF, got %v", i, err)
+		}
+	}
+}
+
+func TestDecoder_ReadFrame_Stream(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+
+	cfg := Config{
+		Bitstream:       hdlc.Config{Unpacked: true, NRZI: true, PreambleFlags: 2},
+		TagMaxBitErrors: 8,
+	}
+	enc, err := NewEncoder(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// noise precedes the first frame
+	stream := make([]byte, 1000)
+	for i := range stream {
+		stream[i] = byte(rng.Intn(2))
+	}
+
+	frms := [][]byte{
+		[]byte("first"),
+		[]byte("second"),
+		[]byte("third"),
+	}
+	starts := make([]int, len(frms))
+	for i, frm := range frms {
+		starts[i] = len(stream)
+		if stream, err = enc.AppendFrame(stream, frm); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Flip a run of levels in the middle of the second codeblock. With
+	// NRZI, this only corrupts the bits at either end of the run.
+	mid := starts[1] + 16*8 + 24*8
+	for i := mid; i < mid+64; i++ {
+		stream[i] ^= 1
+	}
+
+	// Corrupt the third codeblock beyond repair.
+	for i := starts[2] + 16*8 + 64; i < len(stream); i += 31 {
+		stream[i] ^= 1
+	}
+
+	dec, err := NewDecoder(bytes.NewReader(stream), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got [][]byte
+	var corrected int
+	var decErrs int
+	for {
+		frm, anns, err := dec.ReadFrameAnnotated()
+		if err == io.EOF {
+			break
+		} else if errors.Is(err, satcom.ErrDecodeFailure) {
+			decErrs++
+			continue
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, append([]byte{}, frm...))
+		corrected += anns[2].Value.(int)
+	}
+
+	want := frms[:2]
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=%q got=%q", want, got)
+	}
+	if corrected == 0 || corrected > 2 {
+		t.Errorf("unexpected corrected symbols: %d", corrected)
+	}
+	if decErrs != 1 {
+		t.Errorf("unexpected decode failures: want=1 got=%d", decErrs)
+	}
+}
+
+func TestNewDecoder_Invalid(t *testing.T) {
+	if _, err := NewDecoder(bytes.NewReader(nil), Config{TagMaxBitErrors: 16}); err == nil {
+		t.Errorf("expected error")
+	}
+}
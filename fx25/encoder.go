@@ -0,0 +1,117 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package fx25
+
+import (
+	"fmt"
+
+	"github.com/antaris-inc/go-satcom/hdlc"
+	"github.com/antaris-inc/go-satcom/internal/bitstream"
+)
+
+// Encodes frames into an FX.25 bitstream. The encoder retains state
+// between frames, so a single Encoder must be used for each stream.
+type Encoder struct {
+	cfg Config
+
+	// produces the unpacked bits of the HDLC frame placed in each
+	// codeblock
+	hdlc *hdlc.Encoder
+
+	// scratch space for the HDLC frame and codeblock
+	frm   []byte
+	block []byte
+
+	bits *bitstream.Writer
+}
+
+func NewEncoder(cfg Config) (*Encoder, error) {
+	if err := cfg.Err(); err != nil {
+		return nil, err
+	}
+	return newEncoder(cfg), nil
+}
+
+// Build an Encoder using a config that has already been validated.
+func newEncoder(cfg Config) *Encoder {
+	if cfg.CheckBytes == 0 {
+		cfg.CheckBytes = 16
+	}
+	if cfg.Bitstream.PreambleFlags == 0 {
+		cfg.Bitstream.PreambleFlags = 1
+	}
+
+	e := Encoder{
+		cfg:  cfg,
+		hdlc: mustNewHDLCEncoder(),
+		bits: bitstream.NewWriter(!cfg.Bitstream.Unpacked, cfg.Bitstream.NRZI),
+	}
+	return &e
+}
+
+// Append the encoded form of a single frame to dst, including the
+// correlation tag, and the HDLC flags and FCS within the codeblock.
+// When packing bits, the final byte is padded with ones (as on an
+// idle line). This implements the satcom.FrameEncoder interface.
+func (e *Encoder) AppendFrame(dst, frm []byte) ([]byte, error) {
+	var err error
+	e.frm, err = e.hdlc.AppendFrame(e.frm[:0], frm)
+	if err != nil {
+		return dst, err
+	}
+
+	n := (len(e.frm) + 7) / 8
+	tag := e.cfg.Tag
+	if tag == 0 {
+		if tag, err = selectTag(e.cfg.CheckBytes, n); err != nil {
+			return dst, err
+		}
+	} else if n > tag.DataSize() {
+		return dst, fmt.Errorf("frame exceeds %v codeblock", tag)
+	}
+
+	// The HDLC frame is packed least significant bit first, with the
+	// remainder of the codeblock filled by repeating flags.
+	block := e.block[:0]
+	for i := 0; i < tag.DataSize()*8; i++ {
+		var bit byte
+		if i < len(e.frm) {
+			bit = e.frm[i]
+		} else {
+			bit = (hdlc.FLAG >> ((i - len(e.frm)) % 8)) & 1
+		}
+		if i%8 == 0 {
+			block = append(block, 0)
+		}
+		block[i/8] |= bit << (i % 8)
+	}
+	block = codes[tag.CheckSize()].AppendParity(block, block)
+	e.block = block
+
+	for i := 0; i < e.cfg.Bitstream.PreambleFlags; i++ {
+		dst = e.bits.AppendByteLSB(dst, hdlc.FLAG)
+	}
+
+	v := tag.Value()
+	for i := 0; i < TAG_LENGTH_BITS/8; i++ {
+		dst = e.bits.AppendByteLSB(dst, byte(v>>(8*i)))
+	}
+
+	for _, b := range block {
+		dst = e.bits.AppendByteLSB(dst, b)
+	}
+
+	return e.bits.Flush(dst, 1), nil
+}
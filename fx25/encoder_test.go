@@ -0,0 +1,149 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package fx25
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	satcom "github.com/antaris-inc/go-satcom"
+	"github.com/antaris-inc/go-satcom/hdlc"
+)
+
+func TestEncoder_AppendFrame(t *testing.T) {
+	tests := []struct {
+		cfg Config
+		frm []byte
+		tag Tag
+	}{
+		{cfg: Config{}, frm: []byte("hello"), tag: TAG_RS_48_32},
+		{cfg: Config{}, frm: bytes.Repeat([]byte{0xFF}, 32), tag: TAG_RS_80_64},
+		{cfg: Config{CheckBytes: 32}, frm: []byte("hello"), tag: TAG_RS_64_32},
+		{cfg: Config{CheckBytes: 64}, frm: []byte("hello"), tag: TAG_RS_128_64},
+		{cfg: Config{Tag: TAG_RS_255_239}, frm: []byte("hello"), tag: TAG_RS_255_239},
+		{cfg: Config{Bitstream: hdlc.Config{PreambleFlags: 3}}, frm: []byte("hello"), tag: TAG_RS_48_32},
+	}
+
+	for i, tt := range tests {
+		enc, err := NewEncoder(tt.cfg)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		got, err := enc.AppendFrame(nil, tt.frm)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+
+		// Preamble flags are followed by the tag, with each byte
+		// bit-reversed as bits are packed MSB first.
+		preamble := tt.cfg.Bitstream.PreambleFlags
+		if preamble == 0 {
+			preamble = 1
+		}
+		want := bytes.Repeat([]byte{hdlc.FLAG}, preamble)
+		for j := 0; j < 8; j++ {
+			want = append(want, reverse(byte(tt.tag.Value()>>(8*j))))
+		}
+		// codeblock begins with an HDLC flag
+		want = append(want, hdlc.FLAG)
+
+		if !bytes.HasPrefix(got, want) {
+			t.Errorf("case %d: unexpected prefix: want=% x got=% x", i, want, got[:len(want)])
+		}
+		if n := preamble + 8 + tt.tag.BlockSize(); len(got) != n {
+			t.Errorf("case %d: unexpected length: want=%d got=%d", i, n, len(got))
+		}
+	}
+}
+
+func TestEncoder_AppendFrame_Invalid(t *testing.T) {
+	tests := []struct {
+		cfg Config
+		frm []byte
+	}{
+		{cfg: Config{}, frm: nil},
+		{cfg: Config{}, frm: make([]byte, 240)},
+		{cfg: Config{CheckBytes: 64}, frm: make([]byte, 190)},
+		{cfg: Config{Tag: TAG_RS_48_32}, frm: make([]byte, 30)},
+	}
+
+	for i, tt := range tests {
+		enc, err := NewEncoder(tt.cfg)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if _, err := enc.AppendFrame(nil, tt.frm); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+// FX.25 frames must remain decodable by an HDLC decoder.
+func TestEncoder_HDLCCompatible(t *testing.T) {
+	bitstream := hdlc.Config{NRZI: true, PreambleFlags: 2}
+	enc, err := NewEncoder(Config{Bitstream: bitstream})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := [][]byte{
+		[]byte("hello"),
+		bytes.Repeat([]byte{0xFF}, 64),
+		[]byte("world"),
+	}
+
+	var stream []byte
+	for _, frm := range want {
+		if stream, err = enc.AppendFrame(stream, frm); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	dec, err := hdlc.NewDecoder(bytes.NewReader(stream), bitstream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got [][]byte
+	for {
+		frm, err := dec.ReadFrame()
+		if err == io.EOF {
+			break
+		} else if errors.Is(err, satcom.ErrDecodeFailure) {
+			continue
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, append([]byte{}, frm...))
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=% x got=% x", want, got)
+	}
+}
+
+func reverse(b byte) byte {
+	var v byte
+	for i := 0; i < 8; i++ {
+		v = v<<1 | (b>>i)&1
+	}
+	return v
+}
@@ -0,0 +1,233 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package fx25 implements FX.25, which adds Reed-Solomon forward error
+// correction to HDLC frames such as those carrying AX.25. Each HDLC frame,
+// including its flags and FCS, is placed in an RS codeblock identified by
+// a preceding 64-bit correlation tag. As the HDLC frame within is left
+// intact, FX.25 transmissions remain decodable by receivers without FX.25
+// support, such as the hdlc package.
+package fx25
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/antaris-inc/go-satcom/hdlc"
+	"github.com/antaris-inc/go-satcom/internal/rs"
+)
+
+const (
+	// Length of the correlation tag preceding each codeblock
+	TAG_LENGTH_BITS = 64
+
+	// Largest number of bit errors that may be tolerated in a
+	// correlation tag, as all tags differ in 32 bits
+	MAX_TAG_BIT_ERRORS = 15
+
+	// Largest HDLC frame that may be carried, including flags, FCS
+	// and bit stuffing
+	MAX_DATA_SIZE = 239
+
+	// First consecutive root of the RS generator polynomial
+	RS_FIRST_ROOT = 1
+)
+
+// Identifies the RS code used for a codeblock, as indicated by its
+// correlation tag.
+type Tag uint8
+
+const (
+	TAG_RS_255_239 Tag = 0x01
+	TAG_RS_144_128 Tag = 0x02
+	TAG_RS_80_64   Tag = 0x03
+	TAG_RS_48_32   Tag = 0x04
+	TAG_RS_255_223 Tag = 0x05
+	TAG_RS_160_128 Tag = 0x06
+	TAG_RS_96_64   Tag = 0x07
+	TAG_RS_64_32   Tag = 0x08
+	TAG_RS_255_191 Tag = 0x09
+	TAG_RS_192_128 Tag = 0x0A
+	TAG_RS_128_64  Tag = 0x0B
+)
+
+type tagInfo struct {
+	value     uint64
+	blockSize int
+	dataSize  int
+}
+
+// Correlation tag values and codeblock sizes, indexed by Tag. Tags 0x00
+// and 0x0C-0x0F are reserved.
+var tags = [...]tagInfo{
+	TAG_RS_255_239: {0xB74DB7DF8A532F3E, 255, 239},
+	TAG_RS_144_128: {0x26FF60A600CC8FDE, 144, 128},
+	TAG_RS_80_64:   {0xC7DC0508F3D9B09E, 80, 64},
+	TAG_RS_48_32:   {0x8F056EB4369660EE, 48, 32},
+	TAG_RS_255_223: {0x6E260B1AC5835FAE, 255, 223},
+	TAG_RS_160_128: {0xFF94DC634F1CFF4E, 160, 128},
+	TAG_RS_96_64:   {0x1EB7B9CDBC09C00E, 96, 64},
+	TAG_RS_64_32:   {0xDBF869BD2DBB1776, 64, 32},
+	TAG_RS_255_191: {0x3ADB0C13DEAE2836, 255, 191},
+	TAG_RS_192_128: {0xAB69DB6A543188D6, 192, 128},
+	TAG_RS_128_64:  {0x4A4ABEC4A724B796, 128, 64},
+}
+
+// RS codes, indexed by number of check bytes
+var codes = map[int]*rs.Code{}
+
+func init() {
+	for _, n := range []int{16, 32, 64} {
+		c, err := rs.New(n, RS_FIRST_ROOT)
+		if err != nil {
+			panic(err)
+		}
+		codes[n] = c
+	}
+}
+
+// Config of the HDLC frame within each codeblock, with bits held one
+// per byte while the codeblock is assembled or decoded.
+var codeblockHDLC = hdlc.Config{Unpacked: true}
+
+// As with the RS codes, the HDLC config is fixed, so any error is a bug.
+func mustNewHDLCEncoder() *hdlc.Encoder {
+	enc, err := hdlc.NewEncoder(codeblockHDLC)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+func mustNewHDLCDecoder() *hdlc.Decoder {
+	dec, err := hdlc.NewDecoder(nil, codeblockHDLC)
+	if err != nil {
+		panic(err)
+	}
+	return dec
+}
+
+func (t Tag) Err() error {
+	if int(t) >= len(tags) || tags[t].value == 0 {
+		return fmt.Errorf("unsupported tag 0x%02X", uint8(t))
+	}
+	return nil
+}
+
+// Returns the 64-bit correlation tag, sent least significant bit first.
+func (t Tag) Value() uint64 {
+	return tags[t].value
+}
+
+// Returns the number of bytes in the codeblock.
+func (t Tag) BlockSize() int {
+	return tags[t].blockSize
+}
+
+// Returns the number of bytes in the codeblock available for the HDLC
+// frame.
+func (t Tag) DataSize() int {
+	return tags[t].dataSize
+}
+
+// Returns the number of RS check bytes in the codeblock.
+func (t Tag) CheckSize() int {
+	return tags[t].blockSize - tags[t].dataSize
+}
+
+func (t Tag) String() string {
+	if t.Err() != nil {
+		return fmt.Sprintf("Tag(0x%02X)", uint8(t))
+	}
+	return fmt.Sprintf("RS(%d,%d)", t.BlockSize(), t.DataSize())
+}
+
+// Choose the smallest codeblock with the given number of check bytes able
+// to hold n bytes of data.
+func selectTag(checkBytes, n int) (Tag, error) {
+	var best Tag
+	for t := range tags {
+		tag := Tag(t)
+		if tag.Err() != nil || tag.CheckSize() != checkBytes || tag.DataSize() < n {
+			continue
+		}
+		if best == 0 || tag.BlockSize() < best.BlockSize() {
+			best = tag
+		}
+	}
+	if best == 0 {
+		return 0, fmt.Errorf("no codeblock with %d check bytes holds %d bytes", checkBytes, n)
+	}
+	return best, nil
+}
+
+// Locate the tag nearest to the provided value, if within maxBitErrors.
+func matchTag(v uint64, maxBitErrors int) (Tag, int, bool) {
+	var best Tag
+	bestErrs := maxBitErrors + 1
+	for t := range tags {
+		if tags[t].value == 0 {
+			continue
+		}
+		if errs := bits.OnesCount64(v ^ tags[t].value); errs < bestErrs {
+			best, bestErrs = Tag(t), errs
+		}
+	}
+	return best, bestErrs, best != 0
+}
+
+type Config struct {
+	// Representation of the bitstream, as used for HDLC. The preamble
+	// flags precede the correlation tag.
+	Bitstream hdlc.Config
+
+	// Number of RS check bytes added to each frame: 16, 32 or 64,
+	// defaulting to 16. Up to half this many corrupted bytes may be
+	// corrected. The smallest codeblock able to hold each frame is
+	// used, unless Tag is set.
+	CheckBytes int
+
+	// Use the given codeblock for all frames sent, in place of
+	// CheckBytes. Decoding accepts all codeblocks regardless.
+	Tag Tag
+
+	// Maximum number of bit errors tolerated when searching for a
+	// correlation tag.
+	TagMaxBitErrors int
+}
+
+func (cfg *Config) Err() error {
+	if err := cfg.Bitstream.Err(); err != nil {
+		return err
+	}
+
+	switch cfg.CheckBytes {
+	case 0, 16, 32, 64:
+	default:
+		return errors.New("CheckBytes must be 16, 32 or 64")
+	}
+
+	if cfg.Tag != 0 {
+		if err := cfg.Tag.Err(); err != nil {
+			return fmt.Errorf("Tag: %v", err)
+		}
+	}
+
+	if cfg.TagMaxBitErrors < 0 || cfg.TagMaxBitErrors > MAX_TAG_BIT_ERRORS {
+		return fmt.Errorf("TagMaxBitErrors must be 0-%d", MAX_TAG_BIT_ERRORS)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package fx25
+
+import (
+	"testing"
+
+	"github.com/antaris-inc/go-satcom/hdlc"
+)
+
+func TestTag(t *testing.T) {
+	tests := []struct {
+		tag   Tag
+		value uint64
+		n     int
+		k     int
+		str   string
+	}{
+		{tag: TAG_RS_255_239, value: 0xB74DB7DF8A532F3E, n: 255, k: 239, str: "RS(255,239)"},
+		{tag: TAG_RS_48_32, value: 0x8F056EB4369660EE, n: 48, k: 32, str: "RS(48,32)"},
+		{tag: TAG_RS_255_223, value: 0x6E260B1AC5835FAE, n: 255, k: 223, str: "RS(255,223)"},
+		{tag: TAG_RS_128_64, value: 0x4A4ABEC4A724B796, n: 128, k: 64, str: "RS(128,64)"},
+	}
+
+	for i, tt := range tests {
+		if err := tt.tag.Err(); err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if tt.tag.Value() != tt.value {
+			t.Errorf("case %d: unexpected value: want=%x got=%x", i, tt.value, tt.tag.Value())
+		}
+		if tt.tag.BlockSize() != tt.n || tt.tag.DataSize() != tt.k || tt.tag.CheckSize() != tt.n-tt.k {
+			t.Errorf("case %d: unexpected sizes: n=%d k=%d", i, tt.tag.BlockSize(), tt.tag.DataSize())
+		}
+		if tt.tag.String() != tt.str {
+			t.Errorf("case %d: unexpected string: want=%q got=%q", i, tt.str, tt.tag.String())
+		}
+	}
+
+	for _, tag := range []Tag{0x00, 0x0C, 0x0F, 0xFF} {
+		if err := tag.Err(); err == nil {
+			t.Errorf("expected error for tag %v", tag)
+		}
+	}
+}
+
+func TestSelectTag(t *testing.T) {
+	tests := []struct {
+		checkBytes int
+		n          int
+		want       Tag
+		wantErr    bool
+	}{
+		{checkBytes: 16, n: 1, want: TAG_RS_48_32},
+		{checkBytes: 16, n: 33, want: TAG_RS_80_64},
+		{checkBytes: 16, n: 239, want: TAG_RS_255_239},
+		{checkBytes: 16, n: 240, wantErr: true},
+		{checkBytes: 32, n: 32, want: TAG_RS_64_32},
+		{checkBytes: 32, n: 129, want: TAG_RS_255_223},
+		{checkBytes: 64, n: 65, want: TAG_RS_192_128},
+		{checkBytes: 64, n: 192, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		got, err := selectTag(tt.checkBytes, tt.n)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("case %d: expected error", i)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("case %d: unexpected result: want=%v got=%v", i, tt.want, got)
+		}
+	}
+}
+
+func TestMatchTag(t *testing.T) {
+	tests := []struct {
+		v            uint64
+		maxBitErrors int
+		want         Tag
+		wantErrs     int
+		wantOK       bool
+	}{
+		{v: TAG_RS_96_64.Value(), want: TAG_RS_96_64, wantOK: true},
+		{v: TAG_RS_96_64.Value() ^ 0x8000000000000101, maxBitErrors: 3, want: TAG_RS_96_64, wantErrs: 3, wantOK: true},
+		{v: TAG_RS_96_64.Value() ^ 0x8000000000000101, maxBitErrors: 2},
+		{v: TAG_RS_96_64.Value() ^ 0x1, maxBitErrors: 0},
+		{v: 0x566ED2717946107E, maxBitErrors: 4},
+	}
+
+	for i, tt := range tests {
+		got, errs, ok := matchTag(tt.v, tt.maxBitErrors)
+		if ok != tt.wantOK {
+			t.Errorf("case %d: unexpected match: want=%v got=%v", i, tt.wantOK, ok)
+			continue
+		}
+		if ok && (got != tt.want || errs != tt.wantErrs) {
+			t.Errorf("case %d: unexpected result: want=%v/%d got=%v/%d", i, tt.want, tt.wantErrs, got, errs)
+		}
+	}
+}
+
+func TestConfig_Err(t *testing.T) {
+	tests := []struct {
+		cfg     Config
+		wantErr bool
+	}{
+		{cfg: Config{}},
+		{cfg: Config{CheckBytes: 64, TagMaxBitErrors: MAX_TAG_BIT_ERRORS}},
+		{cfg: Config{Tag: TAG_RS_255_191}},
+		{cfg: Config{CheckBytes: 8}, wantErr: true},
+		{cfg: Config{Tag: 0x0C}, wantErr: true},
+		{cfg: Config{TagMaxBitErrors: -1}, wantErr: true},
+		{cfg: Config{TagMaxBitErrors: MAX_TAG_BIT_ERRORS + 1}, wantErr: true},
+		{cfg: Config{Bitstream: hdlc.Config{PreambleFlags: -1}}, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		err := tt.cfg.Err()
+		if tt.wantErr && err == nil {
+			t.Errorf("case %d: expected error", i)
+		} else if !tt.wantErr && err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+		}
+	}
+}
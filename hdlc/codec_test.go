@@ -0,0 +1,100 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package hdlc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	satcom "github.com/antaris-inc/go-satcom"
+)
+
+func TestCodec_FrameSenderReceiver(t *testing.T) {
+	codec, err := NewCodec(Config{NRZI: true, PreambleFlags: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg := satcom.FrameConfig{
+		Codec:     codec,
+		FrameSize: 64,
+	}
+
+	var buf bytes.Buffer
+	fs, err := satcom.NewFrameSender(cfg, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgs := [][]byte{
+		[]byte("hello"),
+		bytes.Repeat([]byte{0xFF}, 64),
+		[]byte("world"),
+	}
+	for i, msg := range msgs {
+		if err := fs.Send(msg); err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+	}
+	if err := fs.Send(make([]byte, 65)); err == nil {
+		t.Errorf("expected error for oversized message")
+	}
+
+	// Corrupt the middle frame. With NRZI, this results in two bit
+	// errors, which may also split the frame in two.
+	stream := buf.Bytes()
+	stream[len(stream)/2] ^= 0x08
+
+	fr, err := satcom.NewFrameReceiver(cfg, bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got [][]byte
+	var decErrs int
+	for {
+		frm, err := fr.Next(context.Background())
+		if err == io.EOF {
+			break
+		} else if errors.Is(err, satcom.ErrDecodeFailure) {
+			decErrs++
+			continue
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, append([]byte{}, frm.Payload...))
+	}
+
+	want := [][]byte{msgs[0], msgs[2]}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=% x got=% x", want, got)
+	}
+	if decErrs == 0 {
+		t.Errorf("expected decode failure")
+	}
+	if stats := fr.Stats(); stats.CodecErrors != uint64(decErrs) {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestNewCodec_Invalid(t *testing.T) {
+	if _, err := NewCodec(Config{PreambleFlags: -1}); err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -0,0 +1,230 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package hdlc
+
+import (
+	"fmt"
+	"io"
+
+	satcom "github.com/antaris-inc/go-satcom"
+	"github.com/antaris-inc/go-satcom/internal/bitstream"
+)
+
+// Locates and decodes frames within a bitstream. A single flag may both
+// close one frame and open the next, and runs of seven or more ones abort
+// the current frame. Frames are only returned once their FCS is verified.
+type Decoder struct {
+	cfg  Config
+	bits *bitstream.Reader
+
+	// length of the current run of ones
+	ones int
+
+	// set once a flag has opened a frame
+	inFrame bool
+
+	// set once an error has been found in the current frame, which
+	// is then discarded up to the next flag
+	failed error
+
+	// octets of the current frame
+	buf  []byte
+	cur  byte
+	curN int
+
+	// Up to six of the most recent data bits, which are not yet known
+	// to be part of the frame as they may be the start of a flag.
+	hold  byte
+	holdN int
+
+	// frame contents last returned
+	frm []byte
+}
+
+func NewDecoder(src io.Reader, cfg Config) (*Decoder, error) {
+	if err := cfg.Err(); err != nil {
+		return nil, err
+	}
+	return newDecoder(src, cfg), nil
+}
+
+// Build a Decoder using a config that has already been validated.
+func newDecoder(src io.Reader, cfg Config) *Decoder {
+	d := Decoder{
+		cfg:  cfg,
+		bits: bitstream.NewReader(src, !cfg.Unpacked, cfg.NRZI),
+	}
+	return &d
+}
+
+// Discard any partial frame, and continue decoding from the provided
+// source. This allows a single Decoder to be reused for many streams.
+func (d *Decoder) Reset(src io.Reader) {
+	d.bits.Reset(src)
+	d.ones = 0
+	d.inFrame = false
+	d.reset()
+	d.frm = nil
+}
+
+// Read the contents of the next valid frame, with FCS removed. Malformed
+// frames are reported using errors that wrap satcom.ErrDecodeFailure,
+// following which decoding may continue. The returned slice is only valid
+// until the next call. This implements the satcom.FrameDecoder interface.
+func (d *Decoder) ReadFrame() ([]byte, error) {
+	// the previous frame is no longer referenced
+	if d.frm != nil {
+		d.buf = d.buf[:0]
+		d.frm = nil
+	}
+
+	for {
+		bit, err := d.bits.ReadBit()
+		if err != nil {
+			return nil, err
+		}
+
+		frm, err := d.processBit(bit)
+		if frm != nil || err != nil {
+			return frm, err
+		}
+	}
+}
+
+// Process a single bit, returning a frame if the bit completes one.
+func (d *Decoder) processBit(bit byte) ([]byte, error) {
+	if bit == 1 {
+		d.ones++
+		switch {
+		case d.ones < 6:
+			d.addBit(1)
+		case d.ones == 7:
+			return nil, d.abort()
+		}
+
+		// a sixth one may only be part of a flag or abort
+		return nil, nil
+	}
+
+	ones := d.ones
+	d.ones = 0
+
+	switch {
+	case ones == 5:
+		// stuffed bit
+		return nil, nil
+	case ones == 6:
+		return d.flag()
+	case ones > 6:
+		// end of an abort or idle line
+		return nil, nil
+	}
+
+	d.addBit(0)
+	return nil, nil
+}
+
+func (d *Decoder) addBit(bit byte) {
+	if !d.inFrame || d.failed != nil {
+		return
+	}
+
+	if d.holdN < 6 {
+		d.hold = d.hold<<1 | bit
+		d.holdN++
+		return
+	}
+
+	oldest := (d.hold >> 5) & 1
+	d.hold = (d.hold<<1 | bit) & 0x3F
+
+	d.cur |= oldest << d.curN
+	d.curN++
+	if d.curN < 8 {
+		return
+	}
+
+	if len(d.buf) >= MAX_FRAME_SIZE {
+		d.failed = fmt.Errorf("%w: frame exceeds max size %d", satcom.ErrDecodeFailure, MAX_FRAME_SIZE)
+		return
+	}
+	d.buf = append(d.buf, d.cur)
+	d.cur, d.curN = 0, 0
+}
+
+// Discard the current frame, returning any error describing it.
+func (d *Decoder) reset() error {
+	hasData := len(d.buf) > 0
+	failed := d.failed
+
+	d.buf = d.buf[:0]
+	d.cur, d.curN = 0, 0
+	d.hold, d.holdN = 0, 0
+	d.failed = nil
+
+	if failed != nil {
+		return failed
+	}
+	if hasData {
+		return fmt.Errorf("%w: frame aborted", satcom.ErrDecodeFailure)
+	}
+	return nil
+}
+
+// Handle a run of seven ones, which aborts any frame in progress. The
+// line is then considered idle until the next flag.
+func (d *Decoder) abort() error {
+	if !d.inFrame {
+		return nil
+	}
+	d.inFrame = false
+	return d.reset()
+}
+
+// Handle a flag, which closes any frame in progress and opens the next.
+func (d *Decoder) flag() ([]byte, error) {
+	wasInFrame := d.inFrame
+	d.inFrame = true
+
+	// the held bits are the start of this flag
+	d.hold, d.holdN = 0, 0
+
+	if !wasInFrame {
+		return nil, nil
+	}
+
+	// Less than an octet between flags is simply fill, such as a
+	// repeated flag or the padding added by an Encoder.
+	if d.failed == nil && len(d.buf) == 0 {
+		d.reset()
+		return nil, nil
+	}
+
+	if d.failed == nil && d.curN != 0 {
+		d.failed = fmt.Errorf("%w: frame is not a whole number of octets", satcom.ErrDecodeFailure)
+	}
+	if d.failed != nil {
+		return nil, d.reset()
+	}
+
+	frm, err := CheckFCS(d.buf)
+	if err != nil {
+		d.reset()
+		return nil, fmt.Errorf("%w: %v", satcom.ErrDecodeFailure, err)
+	}
+
+	d.frm = frm
+	return frm, nil
+}
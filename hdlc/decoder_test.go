@@ -0,0 +1,255 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package hdlc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"testing/iotest"
+
+	satcom "github.com/antaris-inc/go-satcom"
+)
+
+// Encode each frame using a single encoder, returning the full stream.
+func encodeFrames(t *testing.T, cfg Config, frames ...[]byte) []byte {
+	enc, err := NewEncoder(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out []byte
+	for _, frm := range frames {
+		if out, err = enc.AppendFrame(out, frm); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return out
+}
+
+type decodeResult struct {
+	frm    []byte
+	decErr bool
+}
+
+// Decode all frames from the input, which must end with io.EOF.
+func decodeAll(t *testing.T, cfg Config, src io.Reader) []decodeResult {
+	dec, err := NewDecoder(src, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []decodeResult
+	for {
+		frm, err := dec.ReadFrame()
+		if err == io.EOF {
+			return got
+		} else if errors.Is(err, satcom.ErrDecodeFailure) {
+			got = append(got, decodeResult{decErr: true})
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else {
+			got = append(got, decodeResult{frm: append([]byte{}, frm...)})
+		}
+	}
+}
+
+func TestDecoder_RoundTrip(t *testing.T) {
+	frames := [][]byte{
+		{0x01},
+		{0x7E, 0x7E, 0xFF, 0xFF, 0x00},
+		bytes.Repeat([]byte{0xF8}, 300),
+	}
+
+	want := []decodeResult{}
+	for _, frm := range frames {
+		want = append(want, decodeResult{frm: frm})
+	}
+
+	for _, unpacked := range []bool{false, true} {
+		for _, nrzi := range []bool{false, true} {
+			for _, preamble := range []int{0, 4} {
+				// the first NRZI bit following noise cannot be decoded,
+				// so a single flag is insufficient
+				if nrzi && preamble == 0 {
+					preamble = 2
+				}
+
+				cfg := Config{Unpacked: unpacked, NRZI: nrzi, PreambleFlags: preamble}
+				input := encodeFrames(t, cfg, frames...)
+
+				// leading noise is ignored
+				noise := []byte{0x12, 0x34, 0xFF, 0x01}
+				if unpacked {
+					noise = []byte{0, 1, 1, 0, 1, 0, 0, 1}
+				}
+				input = append(noise, input...)
+
+				desc := fmt.Sprintf("unpacked=%v nrzi=%v preamble=%d", unpacked, nrzi, preamble)
+				got := decodeAll(t, cfg, iotest.HalfReader(bytes.NewReader(input)))
+				if !reflect.DeepEqual(want, got) {
+					t.Errorf("%s: unexpected result: want=%v got=%v", desc, want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestDecoder_NRZIInverted(t *testing.T) {
+	cfg := Config{Unpacked: true, NRZI: true, PreambleFlags: 2}
+	input := encodeFrames(t, cfg, []byte{0x01, 0x02}, []byte{0x03})
+	for i := range input {
+		input[i] ^= 1
+	}
+
+	want := []decodeResult{
+		{frm: []byte{0x01, 0x02}},
+		{frm: []byte{0x03}},
+	}
+	if got := decodeAll(t, cfg, bytes.NewReader(input)); !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=%v got=%v", want, got)
+	}
+}
+
+func TestDecoder_Impairments(t *testing.T) {
+	cfg := Config{Unpacked: true}
+	frame1 := encodeFrames(t, cfg, []byte{0x01, 0x02})
+	frame2 := encodeFrames(t, cfg, []byte{0x03, 0x04})
+	flag := frame1[:8]
+
+	// bit error within the frame
+	corrupt := append([]byte{}, frame1...)
+	corrupt[12] ^= 1
+
+	// frame aborted part way through
+	aborted := append(append([]byte{}, frame1[:20]...), 1, 1, 1, 1, 1, 1, 1, 1)
+
+	// frame not ending on an octet boundary
+	misaligned := append(append(append([]byte{}, flag...), 0, 1, 0, 1, 0, 0, 1, 1, 0, 0, 1, 0), flag...)
+
+	tests := []struct {
+		input []byte
+		want  []decodeResult
+	}{
+		// closing flag of one frame opens the next
+		{
+			input: append(append([]byte{}, frame1...), frame2[8:]...),
+			want: []decodeResult{
+				{frm: []byte{0x01, 0x02}},
+				{frm: []byte{0x03, 0x04}},
+			},
+		},
+
+		// flags sharing a zero bit
+		{
+			input: append(append(append([]byte{}, frame1...), flag[1:]...), frame2[8:]...),
+			want: []decodeResult{
+				{frm: []byte{0x01, 0x02}},
+				{frm: []byte{0x03, 0x04}},
+			},
+		},
+
+		// FCS mismatch
+		{
+			input: append(append([]byte{}, corrupt...), frame2...),
+			want: []decodeResult{
+				{decErr: true},
+				{frm: []byte{0x03, 0x04}},
+			},
+		},
+
+		// abort, followed by an idle line
+		{
+			input: append(append(append([]byte{}, aborted...), 1, 1, 1, 1, 1, 1, 1, 1), frame2...),
+			want: []decodeResult{
+				{decErr: true},
+				{frm: []byte{0x03, 0x04}},
+			},
+		},
+
+		// misaligned frame
+		{
+			input: append(append([]byte{}, misaligned...), frame2...),
+			want: []decodeResult{
+				{decErr: true},
+				{frm: []byte{0x03, 0x04}},
+			},
+		},
+
+		// idle line following a frame, and a frame without a closing flag
+		{
+			input: append(append(append([]byte{}, frame1...), 1, 1, 1, 1, 1, 1, 1, 1, 1, 1), frame2[:30]...),
+			want: []decodeResult{
+				{frm: []byte{0x01, 0x02}},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		if got := decodeAll(t, cfg, bytes.NewReader(tt.input)); !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: unexpected result: want=%v got=%v", i, tt.want, got)
+		}
+	}
+}
+
+func TestDecoder_MaxFrameSize(t *testing.T) {
+	cfg := Config{}
+	big := make([]byte, MAX_FRAME_SIZE)
+
+	// the encoder refuses oversized frames, so the flag is appended
+	// to a valid frame missing its closing flag
+	input := encodeFrames(t, cfg, big[:MAX_FRAME_SIZE-FCS_LENGTH_BYTES])
+	input = append(input[:len(input)-2], make([]byte, 16)...)
+	input = append(input, encodeFrames(t, cfg, []byte{0x01})...)
+
+	want := []decodeResult{
+		{decErr: true},
+		{frm: []byte{0x01}},
+	}
+	if got := decodeAll(t, cfg, bytes.NewReader(input)); !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=%v got=%v", want, got)
+	}
+}
+
+func TestDecoder_Reset(t *testing.T) {
+	cfg := Config{}
+	first := encodeFrames(t, cfg, []byte{0x01, 0x02, 0x03})
+	second := encodeFrames(t, cfg, []byte{0x04})
+
+	// the first stream ends partway through a frame
+	dec, err := NewDecoder(bytes.NewReader(first[:len(first)-2]), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := dec.ReadFrame(); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+
+	// the partial frame is discarded rather than joined to the next
+	dec.Reset(bytes.NewReader(second))
+	frm, err := dec.ReadFrame()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []byte{0x04}; !reflect.DeepEqual(want, frm) {
+		t.Errorf("unexpected result: want=% x got=% x", want, frm)
+	}
+	if _, err := dec.ReadFrame(); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
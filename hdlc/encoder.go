@@ -0,0 +1,93 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package hdlc
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/antaris-inc/go-satcom/internal/bitstream"
+)
+
+// Encodes frames into a bitstream. The encoder retains state between
+// frames, so a single Encoder must be used for each stream.
+type Encoder struct {
+	cfg  Config
+	bits *bitstream.Writer
+}
+
+func NewEncoder(cfg Config) (*Encoder, error) {
+	if err := cfg.Err(); err != nil {
+		return nil, err
+	}
+	return newEncoder(cfg), nil
+}
+
+// Build an Encoder using a config that has already been validated.
+func newEncoder(cfg Config) *Encoder {
+	if cfg.PreambleFlags == 0 {
+		cfg.PreambleFlags = 1
+	}
+	e := Encoder{
+		cfg:  cfg,
+		bits: bitstream.NewWriter(!cfg.Unpacked, cfg.NRZI),
+	}
+	return &e
+}
+
+// Append the encoded form of a single frame to dst, including flags and
+// FCS. When packing bits, the final byte is padded with ones (as on an
+// idle line) so that the complete frame is returned. This implements the
+// satcom.FrameEncoder interface.
+func (e *Encoder) AppendFrame(dst, frm []byte) ([]byte, error) {
+	if len(frm) == 0 {
+		return dst, errors.New("frame must not be empty")
+	}
+	if len(frm)+FCS_LENGTH_BYTES > MAX_FRAME_SIZE {
+		return dst, errors.New("frame exceeds max size")
+	}
+
+	for i := 0; i < e.cfg.PreambleFlags; i++ {
+		dst = e.bits.AppendByteLSB(dst, FLAG)
+	}
+
+	var fcs [FCS_LENGTH_BYTES]byte
+	binary.LittleEndian.PutUint16(fcs[:], FCS(frm))
+
+	var ones int
+	for _, v := range [][]byte{frm, fcs[:]} {
+		for _, b := range v {
+			for i := 0; i < 8; i++ {
+				bit := (b >> i) & 1
+				dst = e.bits.AppendBit(dst, bit)
+
+				if bit == 0 {
+					ones = 0
+					continue
+				}
+
+				ones++
+				if ones == 5 {
+					dst = e.bits.AppendBit(dst, 0)
+					ones = 0
+				}
+			}
+		}
+	}
+
+	dst = e.bits.AppendByteLSB(dst, FLAG)
+
+	return e.bits.Flush(dst, 1), nil
+}
@@ -0,0 +1,97 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package hdlc
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Convert a string of 0 and 1 characters to one bit per byte.
+func parseBits(s string) []byte {
+	bits := make([]byte, len(s))
+	for i, c := range s {
+		if c == '1' {
+			bits[i] = 1
+		}
+	}
+	return bits
+}
+
+func TestEncoder(t *testing.T) {
+	frm := []byte{0xFF, 0x01}
+
+	// flag, frame (with a stuffed bit following five ones), FCS
+	// (0x0E, 0xE1), flag; each octet is sent LSB first
+	unpacked := parseBits("01111110" + "111110111" + "10000000" + "01110000" + "10000111" + "01111110")
+
+	tests := []struct {
+		cfg  Config
+		want []byte
+	}{
+		{
+			cfg:  Config{Unpacked: true},
+			want: unpacked,
+		},
+		{
+			cfg:  Config{Unpacked: true, PreambleFlags: 3},
+			want: append(parseBits("0111111001111110"), unpacked...),
+		},
+
+		// packed MSB first, with final byte padded with ones
+		{
+			cfg:  Config{},
+			want: []byte{0x7E, 0xFB, 0xC0, 0x38, 0x43, 0xBF, 0x7F},
+		},
+
+		// each zero is a change in level, from an initial level of 0
+		{
+			cfg:  Config{Unpacked: true, NRZI: true},
+			want: parseBits("1111111000000111110101010111101011010111100000001"),
+		},
+	}
+
+	for i, tt := range tests {
+		enc, err := NewEncoder(tt.cfg)
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+
+		got, err := enc.AppendFrame(nil, frm)
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: unexpected result: want=%v got=%v", i, tt.want, got)
+		}
+	}
+}
+
+func TestEncoder_Invalid(t *testing.T) {
+	if _, err := NewEncoder(Config{PreambleFlags: -1}); err == nil {
+		t.Errorf("expected error for negative PreambleFlags")
+	}
+
+	enc, err := NewEncoder(Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := enc.AppendFrame(nil, nil); err == nil {
+		t.Errorf("expected error for empty frame")
+	}
+	if _, err := enc.AppendFrame(nil, make([]byte, MAX_FRAME_SIZE)); err == nil {
+		t.Errorf("expected error for oversized frame")
+	}
+}
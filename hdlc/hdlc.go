@@ -0,0 +1,86 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package hdlc implements HDLC framing of a bitstream, as used by AX.25
+// radios. Frames are delimited by flag sequences (0x7E), with a zero bit
+// stuffed after every run of five ones within a frame so that flags cannot
+// appear in frame data. Each frame carries a CRC-16/X.25 frame check
+// sequence (FCS). Octets are transmitted least significant bit first.
+package hdlc
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/sigurn/crc16"
+)
+
+const (
+	FLAG = 0x7E
+
+	FCS_LENGTH_BYTES = 2
+
+	// Largest frame (including FCS) accepted by a Decoder, guarding
+	// against unbounded growth when reading noise
+	MAX_FRAME_SIZE = 1 << 16
+)
+
+var fcsTable = crc16.MakeTable(crc16.CRC16_X_25)
+
+// Calculate the frame check sequence of the provided frame contents.
+func FCS(v []byte) uint16 {
+	return crc16.Checksum(v, fcsTable)
+}
+
+// Append the frame check sequence of v to v, in transmission order.
+func AppendFCS(v []byte) []byte {
+	return binary.LittleEndian.AppendUint16(v, FCS(v))
+}
+
+// Verify and strip the frame check sequence from the end of v.
+func CheckFCS(v []byte) ([]byte, error) {
+	n := len(v) - FCS_LENGTH_BYTES
+	if n < 1 {
+		return nil, errors.New("frame too short")
+	}
+	if FCS(v[:n]) != binary.LittleEndian.Uint16(v[n:]) {
+		return nil, errors.New("FCS mismatch")
+	}
+	return v[:n], nil
+}
+
+// Describes the representation of the bitstream carrying HDLC frames.
+type Config struct {
+	// The bitstream holds one bit per byte (in the LSB), as produced
+	// by GNU Radio. Otherwise, bits are packed 8 per byte, MSB first.
+	Unpacked bool
+
+	// Apply NRZI encoding to the bitstream, as used by AX.25. A zero
+	// bit is represented by a change in level, and a one bit by no
+	// change, making the stream immune to inversion. As the first bit
+	// received cannot be decoded, PreambleFlags should be at least 2.
+	NRZI bool
+
+	// Number of flags sent ahead of each frame, defaulting to 1.
+	// Additional flags give a receiver time to synchronize, much like
+	// the TXDELAY of a TNC.
+	PreambleFlags int
+}
+
+func (cfg *Config) Err() error {
+	if cfg.PreambleFlags < 0 {
+		return errors.New("PreambleFlags must not be negative")
+	}
+	return nil
+}
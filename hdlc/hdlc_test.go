@@ -0,0 +1,47 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package hdlc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFCS(t *testing.T) {
+	if got := FCS([]byte("123456789")); got != 0x906E {
+		t.Errorf("unexpected result: want=%04x got=%04x", 0x906E, got)
+	}
+
+	frm := AppendFCS([]byte{0xFF, 0x01})
+	if want := []byte{0xFF, 0x01, 0x0E, 0xE1}; !reflect.DeepEqual(want, frm) {
+		t.Errorf("unexpected result: want=% x got=% x", want, frm)
+	}
+
+	got, err := CheckFCS(frm)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []byte{0xFF, 0x01}; !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=% x got=% x", want, got)
+	}
+
+	frm[0] ^= 0x10
+	if _, err := CheckFCS(frm); err == nil {
+		t.Errorf("expected FCS mismatch")
+	}
+	if _, err := CheckFCS(frm[:2]); err == nil {
+		t.Errorf("expected error for short frame")
+	}
+}
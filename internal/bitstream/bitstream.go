@@ -0,0 +1,110 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package bitstream provides helpers for working with bitstreams
+// represented either as packed bytes (8 bits per byte, MSB first)
+// or unpacked bytes (1 bit per byte, stored in the LSB).
+package bitstream
+
+import "io"
+
+// Expands each byte in src into 8 bits (MSB first), storing one bit
+// per byte in dst. The dst slice must be at least 8*len(src) bytes.
+func Unpack(dst, src []byte) {
+	for i, b := range src {
+		for j := 0; j < 8; j++ {
+			dst[i*8+j] = (b >> (7 - j)) & 0x1
+		}
+	}
+}
+
+// Packs unpacked bits in src (1 bit per byte, LSB) into dst, MSB first.
+// The length of src must be a multiple of 8 and dst must be at least
+// len(src)/8 bytes.
+func Pack(dst, src []byte) {
+	for i := 0; i+8 <= len(src); i += 8 {
+		var b byte
+		for j := 0; j < 8; j++ {
+			b = (b << 1) | (src[i+j] & 0x1)
+		}
+		dst[i/8] = b
+	}
+}
+
+// Returns an io.Reader that produces one bit per byte from the provided
+// source. If packed is true, each source byte is expanded into 8 bits
+// (MSB first). Otherwise the source is assumed to already hold one bit
+// per byte, and only the LSB of each byte is retained.
+func NewUnpackingReader(src io.Reader, packed bool) io.Reader {
+	return &unpackingReader{
+		source: src,
+		packed: packed,
+	}
+}
+
+type unpackingReader struct {
+	source io.Reader
+	packed bool
+
+	// scratch space for packed reads
+	buf []byte
+
+	// unpacked bits not yet returned to the caller
+	pending    [8]byte
+	pendingN   int
+	pendingOff int
+}
+
+func (r *unpackingReader) Read(dst []byte) (int, error) {
+	if !r.packed {
+		n, err := r.source.Read(dst)
+		for i := 0; i < n; i++ {
+			dst[i] &= 0x1
+		}
+		return n, err
+	}
+
+	// drain bits remaining from a previous partial read
+	if r.pendingOff < r.pendingN {
+		n := copy(dst, r.pending[r.pendingOff:r.pendingN])
+		r.pendingOff += n
+		return n, nil
+	}
+
+	if len(dst) == 0 {
+		return 0, nil
+	}
+
+	// a partial byte is requested, so unpack a full byte and hold
+	// on to what remains
+	if len(dst) < 8 {
+		n, err := r.source.Read(r.pending[:1])
+		if n == 0 {
+			return 0, err
+		}
+		Unpack(r.pending[:], r.pending[:1])
+		r.pendingN = 8
+		r.pendingOff = copy(dst, r.pending[:])
+		return r.pendingOff, err
+	}
+
+	want := len(dst) / 8
+	if len(r.buf) < want {
+		r.buf = make([]byte, want)
+	}
+
+	n, err := r.source.Read(r.buf[:want])
+	Unpack(dst, r.buf[:n])
+	return n * 8, err
+}
@@ -0,0 +1,85 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package bitstream
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestPackUnpack(t *testing.T) {
+	packed := []byte{0xA5, 0x01}
+	want := []byte{1, 0, 1, 0, 0, 1, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1}
+
+	got := make([]byte, 16)
+	Unpack(got, packed)
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected unpacked result: want=%v got=%v", want, got)
+	}
+
+	repacked := make([]byte, 2)
+	Pack(repacked, got)
+	if !reflect.DeepEqual(packed, repacked) {
+		t.Fatalf("unexpected packed result: want=% x got=% x", packed, repacked)
+	}
+}
+
+func TestUnpackingReader(t *testing.T) {
+	want := []byte{1, 0, 1, 0, 0, 1, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1}
+
+	tests := []struct {
+		src    []byte
+		packed bool
+	}{
+		{src: []byte{0xA5, 0x01}, packed: true},
+
+		// upper bits of unpacked input are ignored
+		{src: []byte{1, 2, 3, 4, 0, 1, 0, 1, 0, 0, 0, 0, 0, 0, 0, 3}, packed: false},
+	}
+
+	for ti, tt := range tests {
+		rd := NewUnpackingReader(bytes.NewBuffer(tt.src), tt.packed)
+		got, err := io.ReadAll(rd)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", ti, err)
+		} else if !reflect.DeepEqual(want, got) {
+			t.Errorf("case %d: unexpected result: want=%v got=%v", ti, want, got)
+		}
+	}
+}
+
+func TestUnpackingReader_ShortReads(t *testing.T) {
+	want := []byte{1, 0, 1, 0, 0, 1, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1}
+
+	rd := NewUnpackingReader(bytes.NewBuffer([]byte{0xA5, 0x01}), true)
+
+	got := []byte{}
+	buf := make([]byte, 3)
+	for {
+		n, err := rd.Read(buf)
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected result: want=%v got=%v", want, got)
+	}
+}
@@ -0,0 +1,82 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package bitstream
+
+import "io"
+
+// Number of bits read from the source at once
+const READER_BUFFER_SIZE = 4096
+
+// Reads bits from a stream one at a time, optionally reversing NRZI
+// encoding. The source holds either packed bits (MSB first) or one bit
+// per byte, as described for NewUnpackingReader.
+type Reader struct {
+	src    io.Reader
+	packed bool
+	nrzi   bool
+
+	// bits read from the source but not yet returned
+	bits []byte
+	pos  int
+	n    int
+	err  error
+
+	// previous NRZI level
+	level byte
+}
+
+func NewReader(src io.Reader, packed, nrzi bool) *Reader {
+	return &Reader{
+		src:    NewUnpackingReader(src, packed),
+		packed: packed,
+		nrzi:   nrzi,
+		bits:   make([]byte, READER_BUFFER_SIZE),
+	}
+}
+
+// Discard any buffered bits and NRZI state, and continue reading from
+// the provided source.
+func (r *Reader) Reset(src io.Reader) {
+	r.src = NewUnpackingReader(src, r.packed)
+	r.pos, r.n = 0, 0
+	r.err = nil
+	r.level = 0
+}
+
+// Read the next bit. An error from the source is returned once all bits
+// read before it have been returned, after which reading may continue.
+func (r *Reader) ReadBit() (byte, error) {
+	for r.pos == r.n {
+		if r.err != nil {
+			err := r.err
+			r.err = nil
+			return 0, err
+		}
+
+		r.n, r.err = r.src.Read(r.bits)
+		r.pos = 0
+	}
+
+	bit := r.bits[r.pos] & 1
+	r.pos++
+
+	if r.nrzi {
+		level := bit
+		bit = ^(level ^ r.level) & 1
+		r.level = level
+	}
+
+	return bit, nil
+}
@@ -0,0 +1,103 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package bitstream
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader_RoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	bits := make([]byte, 800)
+	for i := range bits {
+		bits[i] = byte(rng.Intn(2))
+	}
+
+	tests := []struct {
+		packed bool
+		nrzi   bool
+	}{
+		{packed: true},
+		{packed: true, nrzi: true},
+		{packed: false},
+		{packed: false, nrzi: true},
+	}
+
+	for i, tt := range tests {
+		w := NewWriter(tt.packed, tt.nrzi)
+		var stream []byte
+		for _, bit := range bits {
+			stream = w.AppendBit(stream, bit)
+		}
+
+		r := NewReader(iotest.OneByteReader(bytes.NewReader(stream)), tt.packed, tt.nrzi)
+		var got []byte
+		for {
+			bit, err := r.ReadBit()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("case %d: unexpected error: %v", i, err)
+			}
+			got = append(got, bit)
+		}
+
+		if !reflect.DeepEqual(bits, got) {
+			t.Errorf("case %d: unexpected result: want=%v got=%v", i, bits, got)
+		}
+	}
+}
+
+func TestReader_Errors(t *testing.T) {
+	errTest := errors.New("test")
+	src := io.MultiReader(
+		iotest.DataErrReader(bytes.NewReader([]byte{0x80})),
+		iotest.ErrReader(errTest),
+	)
+	r := NewReader(src, true, false)
+
+	// bits preceding an error are returned first
+	for i, want := range []byte{1, 0, 0, 0, 0, 0, 0, 0} {
+		if bit, err := r.ReadBit(); err != nil || bit != want {
+			t.Fatalf("case %d: unexpected result: bit=%d err=%v", i, bit, err)
+		}
+	}
+
+	if _, err := r.ReadBit(); err != errTest {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// reading may continue, such as from a new source
+	r.Reset(bytes.NewReader([]byte{0x01}))
+	var got []byte
+	for {
+		bit, err := r.ReadBit()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, bit)
+	}
+	if want := []byte{0, 0, 0, 0, 0, 0, 0, 1}; !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=%v got=%v", want, got)
+	}
+}
@@ -0,0 +1,83 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package bitstream
+
+// Appends bits to a stream one at a time, optionally applying NRZI
+// encoding. Bits are either packed 8 per byte (MSB first) or stored one
+// per byte. The writer retains state between calls, so a single Writer
+// must be used for each stream.
+type Writer struct {
+	packed bool
+	nrzi   bool
+
+	// current NRZI level
+	level byte
+
+	// bits of a packed byte still to be filled
+	cur  byte
+	curN int
+}
+
+// Create a Writer. If nrzi is set, a zero bit is represented by a change
+// in level and a one bit by no change.
+func NewWriter(packed, nrzi bool) *Writer {
+	return &Writer{
+		packed: packed,
+		nrzi:   nrzi,
+	}
+}
+
+// Append a single bit to dst. When packing, dst is only extended once
+// a full byte is available.
+func (w *Writer) AppendBit(dst []byte, bit byte) []byte {
+	bit &= 1
+	if w.nrzi {
+		if bit == 0 {
+			w.level ^= 1
+		}
+		bit = w.level
+	}
+
+	if !w.packed {
+		return append(dst, bit)
+	}
+
+	w.cur = w.cur<<1 | bit
+	w.curN++
+	if w.curN < 8 {
+		return dst
+	}
+
+	dst = append(dst, w.cur)
+	w.cur, w.curN = 0, 0
+	return dst
+}
+
+// Append the bits of b to dst, least significant bit first.
+func (w *Writer) AppendByteLSB(dst []byte, b byte) []byte {
+	for i := 0; i < 8; i++ {
+		dst = w.AppendBit(dst, b>>i)
+	}
+	return dst
+}
+
+// Complete any partially filled packed byte by appending the given bit
+// (before NRZI encoding) until it is full.
+func (w *Writer) Flush(dst []byte, fill byte) []byte {
+	for w.packed && w.curN > 0 {
+		dst = w.AppendBit(dst, fill)
+	}
+	return dst
+}
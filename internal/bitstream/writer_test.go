@@ -0,0 +1,64 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package bitstream
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	bits := []byte{1, 0, 1, 1, 0, 0, 0, 0, 1}
+
+	tests := []struct {
+		packed bool
+		nrzi   bool
+		want   []byte
+	}{
+		{packed: true, want: []byte{0xB0, 0xFF}},
+		{packed: true, nrzi: true, want: []byte{0x75, 0xFF}},
+		{packed: false, want: []byte{1, 0, 1, 1, 0, 0, 0, 0, 1}},
+		{packed: false, nrzi: true, want: []byte{0, 1, 1, 1, 0, 1, 0, 1, 1}},
+	}
+
+	for i, tt := range tests {
+		w := NewWriter(tt.packed, tt.nrzi)
+
+		var got []byte
+		for _, bit := range bits {
+			got = w.AppendBit(got, bit)
+		}
+		got = w.Flush(got, 1)
+
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: unexpected result: want=% x got=% x", i, tt.want, got)
+		}
+	}
+}
+
+func TestWriter_AppendByteLSB(t *testing.T) {
+	w := NewWriter(false, false)
+	got := w.AppendByteLSB(nil, 0x7E)
+	want := []byte{0, 1, 1, 1, 1, 1, 1, 0}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=%v got=%v", want, got)
+	}
+
+	// packed bytes are written MSB first, reversing the bit order
+	w = NewWriter(true, false)
+	if got := w.AppendByteLSB(nil, 0x01); !reflect.DeepEqual([]byte{0x80}, got) {
+		t.Errorf("unexpected result: % x", got)
+	}
+}
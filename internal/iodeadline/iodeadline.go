@@ -0,0 +1,31 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package iodeadline describes I/O types that support deadlines, such as
+// net.Conn and *os.File.
+package iodeadline
+
+import (
+	"time"
+)
+
+// Implemented by sources that support read deadlines.
+type Reader interface {
+	SetReadDeadline(time.Time) error
+}
+
+// Implemented by destinations that support write deadlines.
+type Writer interface {
+	SetWriteDeadline(time.Time) error
+}
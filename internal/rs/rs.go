@@ -0,0 +1,279 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package rs implements systematic Reed-Solomon codes over GF(2^8), with
+// the field generated by x^8+x^4+x^3+x^2+1 and the primitive element 2.
+// Shortened codes are supported by using codeblocks of fewer than 255
+// symbols, which are treated as if padded with leading zeros.
+package rs
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// Field generator polynomial
+	FIELD_POLY = 0x11D
+
+	// Number of symbols in a full codeblock
+	MAX_BLOCK_SIZE = 255
+)
+
+var (
+	expTable [2 * MAX_BLOCK_SIZE]byte
+	logTable [256]int
+)
+
+func init() {
+	x := 1
+	for i := 0; i < MAX_BLOCK_SIZE; i++ {
+		expTable[i] = byte(x)
+		expTable[i+MAX_BLOCK_SIZE] = byte(x)
+		logTable[x] = i
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= FIELD_POLY
+		}
+	}
+}
+
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[logTable[a]+logTable[b]]
+}
+
+func div(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return expTable[logTable[a]+MAX_BLOCK_SIZE-logTable[b]]
+}
+
+// Returns the primitive element raised to the power n, which may be
+// negative.
+func alphaPow(n int) byte {
+	n %= MAX_BLOCK_SIZE
+	if n < 0 {
+		n += MAX_BLOCK_SIZE
+	}
+	return expTable[n]
+}
+
+// A Reed-Solomon code with a fixed number of check symbols. Symbols
+// are ordered with the highest degree coefficient first, so the check
+// symbols follow the data in each codeblock.
+type Code struct {
+	nroots int
+
+	// power of the primitive element forming the first root of the
+	// generator polynomial
+	fcr int
+
+	// generator polynomial, lowest degree first, not including the
+	// leading coefficient (which is always 1)
+	gen []byte
+}
+
+// Create a code with the given number of check symbols, and first
+// consecutive root of the generator polynomial.
+func New(nroots, fcr int) (*Code, error) {
+	if nroots <= 0 || nroots >= MAX_BLOCK_SIZE {
+		return nil, errors.New("number of check symbols must be 1-254")
+	}
+	if fcr < 0 || fcr >= MAX_BLOCK_SIZE {
+		return nil, errors.New("first root must be 0-254")
+	}
+
+	// multiply out (x - a^fcr)(x - a^(fcr+1))...
+	gen := make([]byte, nroots+1)
+	gen[0] = 1
+	for i := 0; i < nroots; i++ {
+		root := alphaPow(fcr + i)
+		for j := i + 1; j > 0; j-- {
+			gen[j] = gen[j-1] ^ mul(gen[j], root)
+		}
+		gen[0] = mul(gen[0], root)
+	}
+
+	c := Code{
+		nroots: nroots,
+		fcr:    fcr,
+		gen:    gen[:nroots],
+	}
+	return &c, nil
+}
+
+// Number of check symbols added to each codeblock.
+func (c *Code) CheckSize() int {
+	return c.nroots
+}
+
+// Append the check symbols for the provided data to dst. The data must
+// not exceed MAX_BLOCK_SIZE-CheckSize() symbols.
+func (c *Code) AppendParity(dst, data []byte) []byte {
+	// remainder of data(x)*x^nroots divided by the generator, highest
+	// degree first
+	rem := make([]byte, c.nroots)
+	for _, b := range data {
+		fb := b ^ rem[0]
+		copy(rem, rem[1:])
+		rem[c.nroots-1] = 0
+		if fb == 0 {
+			continue
+		}
+		for j := 0; j < c.nroots; j++ {
+			rem[j] ^= mul(fb, c.gen[c.nroots-1-j])
+		}
+	}
+	return append(dst, rem...)
+}
+
+// Correct errors in the provided codeblock in place, returning the number
+// of symbols corrected. Up to CheckSize()/2 symbol errors may be corrected,
+// and an error is returned if the codeblock cannot be corrected.
+func (c *Code) Decode(block []byte) (int, error) {
+	n := len(block)
+	if n <= c.nroots || n > MAX_BLOCK_SIZE {
+		return 0, fmt.Errorf("codeblock must be %d-%d symbols", c.nroots+1, MAX_BLOCK_SIZE)
+	}
+
+	synd := c.syndromes(block)
+	clean := true
+	for _, s := range synd {
+		if s != 0 {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return 0, nil
+	}
+
+	lambda, nerr := c.locator(synd)
+	if 2*nerr > c.nroots {
+		return 0, errors.New("too many errors")
+	}
+
+	// error evaluator, omega(x) = synd(x) * lambda(x) mod x^nroots
+	omega := make([]byte, c.nroots)
+	for i := range omega {
+		for j := 0; j <= i && j <= nerr; j++ {
+			omega[i] ^= mul(synd[i-j], lambda[j])
+		}
+	}
+
+	// Chien search for roots of the error locator, at the inverse of
+	// the location of each error, followed by Forney's algorithm to
+	// find the error magnitudes.
+	var found int
+	for deg := 0; deg < n; deg++ {
+		xinv := alphaPow(-deg)
+		if eval(lambda[:nerr+1], xinv) != 0 {
+			continue
+		}
+		found++
+
+		// formal derivative of lambda, with only odd terms remaining
+		var dl byte
+		for j := 1; j <= nerr; j += 2 {
+			dl ^= mul(lambda[j], alphaPow(-deg*(j-1)))
+		}
+		if dl == 0 {
+			return 0, errors.New("uncorrectable error")
+		}
+
+		mag := mul(div(eval(omega, xinv), dl), alphaPow(deg*(1-c.fcr)))
+		block[n-1-deg] ^= mag
+	}
+
+	if found != nerr {
+		return 0, errors.New("uncorrectable error")
+	}
+
+	for _, s := range c.syndromes(block) {
+		if s != 0 {
+			return 0, errors.New("uncorrectable error")
+		}
+	}
+
+	return nerr, nil
+}
+
+// Evaluate the codeblock at each root of the generator polynomial.
+func (c *Code) syndromes(block []byte) []byte {
+	synd := make([]byte, c.nroots)
+	for i := range synd {
+		root := alphaPow(c.fcr + i)
+		var s byte
+		for _, b := range block {
+			s = mul(s, root) ^ b
+		}
+		synd[i] = s
+	}
+	return synd
+}
+
+// Find the error locator polynomial (lowest degree first) from the
+// syndromes using the Berlekamp-Massey algorithm, also returning its
+// degree.
+func (c *Code) locator(synd []byte) ([]byte, int) {
+	lambda := make([]byte, c.nroots+1)
+	prev := make([]byte, c.nroots+1)
+	tmp := make([]byte, c.nroots+1)
+	lambda[0], prev[0] = 1, 1
+
+	var l int
+	shift := 1
+	b := byte(1)
+
+	for r := 0; r < c.nroots; r++ {
+		d := synd[r]
+		for i := 1; i <= l; i++ {
+			d ^= mul(lambda[i], synd[r-i])
+		}
+		if d == 0 {
+			shift++
+			continue
+		}
+
+		copy(tmp, lambda)
+		coef := div(d, b)
+		for i := 0; i+shift <= c.nroots; i++ {
+			lambda[i+shift] ^= mul(coef, prev[i])
+		}
+
+		if 2*l <= r {
+			l = r + 1 - l
+			copy(prev, tmp)
+			b = d
+			shift = 1
+		} else {
+			shift++
+		}
+	}
+
+	return lambda, l
+}
+
+// Evaluate a polynomial (lowest degree first) at x.
+func eval(p []byte, x byte) byte {
+	var v byte
+	for i := len(p) - 1; i >= 0; i-- {
+		v = mul(v, x) ^ p[i]
+	}
+	return v
+}
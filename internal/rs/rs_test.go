@@ -0,0 +1,170 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package rs
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestCode_AppendParity(t *testing.T) {
+	seq := make([]byte, 32)
+	for i := range seq {
+		seq[i] = byte(i)
+	}
+
+	tests := []struct {
+		nroots int
+		data   []byte
+		want   []byte
+	}{
+		{
+			nroots: 4,
+			data:   []byte("hello"),
+			want:   []byte{0xe7, 0x2f, 0xc7, 0x75},
+		},
+		{
+			nroots: 16,
+			data:   seq,
+			want: []byte{
+				0x95, 0xc8, 0xf6, 0xc9, 0x30, 0x9d, 0x9b, 0xd9,
+				0x15, 0x01, 0xa2, 0x2e, 0xb5, 0x4c, 0x34, 0x54,
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		c, err := New(tt.nroots, 1)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		got := c.AppendParity(nil, tt.data)
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: unexpected result: want=% x got=% x", i, tt.want, got)
+		}
+	}
+}
+
+func TestCode_Decode(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	tests := []struct {
+		nroots int
+		fcr    int
+		size   int
+		errs   int
+	}{
+		{nroots: 16, fcr: 1, size: 255, errs: 0},
+		{nroots: 16, fcr: 1, size: 255, errs: 1},
+		{nroots: 16, fcr: 1, size: 255, errs: 8},
+		{nroots: 16, fcr: 1, size: 48, errs: 8},
+		{nroots: 32, fcr: 1, size: 96, errs: 16},
+		{nroots: 64, fcr: 1, size: 255, errs: 32},
+		{nroots: 32, fcr: 112, size: 255, errs: 16},
+		{nroots: 4, fcr: 0, size: 10, errs: 2},
+	}
+
+	for i, tt := range tests {
+		c, err := New(tt.nroots, tt.fcr)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+
+		data := make([]byte, tt.size-tt.nroots)
+		rng.Read(data)
+		want := c.AppendParity(append([]byte{}, data...), data)
+
+		got := append([]byte{}, want...)
+		for _, pos := range rng.Perm(tt.size)[:tt.errs] {
+			got[pos] ^= byte(rng.Intn(255) + 1)
+		}
+
+		n, err := c.Decode(got)
+		if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+		if n != tt.errs {
+			t.Errorf("case %d: unexpected corrected count: want=%d got=%d", i, tt.errs, n)
+		}
+		if !bytes.Equal(want, got) {
+			t.Errorf("case %d: unexpected result: want=% x got=% x", i, want, got)
+		}
+	}
+}
+
+func TestCode_Decode_Uncorrectable(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+
+	c, err := New(16, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := make([]byte, 64)
+	rng.Read(data)
+	block := c.AppendParity(append([]byte{}, data...), data)
+
+	// Beyond the correction capacity, decoding must either fail or
+	// produce a different valid codeword, never an invalid block.
+	var failures int
+	for i := 0; i < 100; i++ {
+		got := append([]byte{}, block...)
+		for _, pos := range rng.Perm(len(got))[:12] {
+			got[pos] ^= byte(rng.Intn(255) + 1)
+		}
+		if _, err := c.Decode(got); err != nil {
+			failures++
+			continue
+		}
+		for _, s := range c.syndromes(got) {
+			if s != 0 {
+				t.Fatalf("decode returned invalid codeword")
+			}
+		}
+	}
+	if failures == 0 {
+		t.Errorf("expected decode failures")
+	}
+
+	if _, err := c.Decode(make([]byte, 16)); err == nil {
+		t.Errorf("expected error for short codeblock")
+	}
+	if _, err := c.Decode(make([]byte, 256)); err == nil {
+		t.Errorf("expected error for long codeblock")
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	tests := []struct {
+		nroots int
+		fcr    int
+	}{
+		{nroots: 0, fcr: 1},
+		{nroots: 255, fcr: 1},
+		{nroots: 16, fcr: -1},
+		{nroots: 16, fcr: 255},
+	}
+
+	for i, tt := range tests {
+		if _, err := New(tt.nroots, tt.fcr); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package kiss
+
+import (
+	"errors"
+	"io"
+
+	satcom "github.com/antaris-inc/go-satcom"
+)
+
+type CodecConfig struct {
+	// TNC port on which data frames are sent and received
+	Port uint8
+}
+
+func (cfg *CodecConfig) Err() error {
+	if cfg.Port > MAX_PORT {
+		return errors.New("Port must not exceed 15")
+	}
+	return nil
+}
+
+// Frames data using KISS, for use as the Codec of a satcom.FrameConfig.
+// This allows a FrameSender and FrameReceiver to communicate through a
+// TNC, or with libcsp over a serial link. KISS escaping makes the size
+// of an encoded frame dependent on its contents, so it cannot be used as
+// an Adapter alongside a sync marker.
+type Codec struct {
+	port uint8
+}
+
+func NewCodec(cfg CodecConfig) (*Codec, error) {
+	if err := cfg.Err(); err != nil {
+		return nil, err
+	}
+	return &Codec{port: cfg.Port}, nil
+}
+
+func (c *Codec) NewEncoder() satcom.FrameEncoder {
+	return &codecEncoder{port: c.port}
+}
+
+// Only data frames received on the configured port are returned by
+// the decoder. All other frames are silently discarded.
+func (c *Codec) NewDecoder(src io.Reader) satcom.FrameDecoder {
+	return &codecDecoder{port: c.port, dec: NewDecoder(src)}
+}
+
+type codecEncoder struct {
+	port uint8
+}
+
+func (e *codecEncoder) AppendFrame(dst, frm []byte) ([]byte, error) {
+	return AppendEncode(dst, Frame{Port: e.port, Command: CMD_DATA, Data: frm})
+}
+
+type codecDecoder struct {
+	port uint8
+	dec  *Decoder
+}
+
+func (d *codecDecoder) ReadFrame() ([]byte, error) {
+	for {
+		f, err := d.dec.ReadFrame()
+		if err != nil {
+			return nil, err
+		}
+		if f.Command == CMD_DATA && f.Port == d.port {
+			return f.Data, nil
+		}
+	}
+}
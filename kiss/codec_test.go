@@ -0,0 +1,138 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package kiss
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	satcom "github.com/antaris-inc/go-satcom"
+	"github.com/antaris-inc/go-satcom/crc"
+)
+
+func makeCodecFrameConfig(t *testing.T, port uint8) satcom.FrameConfig {
+	codec, err := NewCodec(CodecConfig{Port: port})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	crc32Adapter, err := crc.NewCRC32Adapter(crc.CRC32AdapterConfig{
+		Algorithm: crc.CRC32c,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return satcom.FrameConfig{
+		Codec:     codec,
+		FrameSize: 256,
+		Adapters:  []satcom.Adapter{crc32Adapter},
+	}
+}
+
+func TestCodec_FrameSenderReceiver(t *testing.T) {
+	cfg := makeCodecFrameConfig(t, 2)
+
+	var buf bytes.Buffer
+	fs, err := satcom.NewFrameSender(cfg, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// payload size may vary, up to FrameSize less CRC
+	if got := fs.MaxPayloadSize(); got != 252 {
+		t.Errorf("unexpected MaxPayloadSize: want=252 got=%d", got)
+	}
+
+	msgs := [][]byte{
+		{0x01, 0x02, 0x03},
+		{FEND, FESC, 0x04},
+		bytes.Repeat([]byte{FEND}, 252),
+	}
+	for i, msg := range msgs {
+		if err := fs.Send(msg); err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+	}
+	if err := fs.Send(make([]byte, 253)); err == nil {
+		t.Errorf("expected error for oversized message")
+	}
+
+	// interleave frames that are not delivered: other ports, commands,
+	// and a corrupted frame
+	enc := NewEncoder(&buf)
+	enc.WriteData(1, []byte{0x05, 0x06, 0x07, 0x08, 0x09})
+	enc.SetTXDelay(2, 0)
+	enc.WriteData(2, []byte{0x05, 0x06, 0x07, 0x08, 0x09})
+
+	fr, err := satcom.NewFrameReceiver(cfg, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	for i, want := range msgs {
+		frm, err := fr.Next(ctx)
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+		if !reflect.DeepEqual(want, frm.Payload) {
+			t.Errorf("case %d: unexpected result: want=% x got=% x", i, want, frm.Payload)
+		}
+	}
+
+	if _, err := fr.Next(ctx); !errors.Is(err, satcom.ErrDecodeFailure) {
+		t.Errorf("expected decode failure, got %v", err)
+	}
+	if _, err := fr.Next(ctx); err != io.EOF {
+		t.Errorf("unexpected error: want=%v got=%v", io.EOF, err)
+	}
+
+	stats := fr.Stats()
+	if stats.FramesReceived != 3 || stats.AdapterErrors[0] != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestCodec_FrameConfigErr(t *testing.T) {
+	base := makeCodecFrameConfig(t, 0)
+
+	tests := []func(*satcom.FrameConfig){
+		func(cfg *satcom.FrameConfig) { cfg.FrameSyncMarker = []byte{0x7E} },
+		func(cfg *satcom.FrameConfig) { cfg.DetectInvertedSyncMarker = true },
+		func(cfg *satcom.FrameConfig) { cfg.ResyncOnFailure = true },
+		func(cfg *satcom.FrameConfig) { cfg.LengthField = &satcom.FrameLengthField{Width: 1} },
+		func(cfg *satcom.FrameConfig) { cfg.StreamFormat = satcom.STREAM_FORMAT_PACKED_BITS },
+	}
+
+	if err := base.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, modify := range tests {
+		cfg := base
+		modify(&cfg)
+		if err := cfg.Err(); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+
+	if _, err := NewCodec(CodecConfig{Port: 16}); err == nil {
+		t.Errorf("expected error for invalid port")
+	}
+}
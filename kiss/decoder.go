@@ -0,0 +1,151 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package kiss
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	satcom "github.com/antaris-inc/go-satcom"
+)
+
+// Reads KISS frames from a stream. Any data preceding the first FEND is
+// discarded, as are empty frames (such as those produced by back-to-back
+// FEND bytes).
+type Decoder struct {
+	src *bufio.Reader
+
+	// State of the frame being decoded, retained if reading from
+	// the source is interrupted part way through.
+	buf     []byte
+	inFrame bool
+	escaped bool
+
+	// set once an error has been found in the current frame, which
+	// is then discarded up to the next FEND
+	failed error
+
+	// frame contents last returned
+	frm []byte
+}
+
+func NewDecoder(src io.Reader) *Decoder {
+	return &Decoder{src: bufio.NewReader(src)}
+}
+
+// Read the next frame from the source. Malformed frames, such as those
+// containing an invalid escape sequence, are reported using errors that
+// wrap satcom.ErrDecodeFailure; decoding may continue following these.
+// The returned Data is only valid until the next call.
+func (d *Decoder) ReadFrame() (Frame, error) {
+	raw, err := d.readRaw()
+	if err != nil {
+		return Frame{}, err
+	}
+
+	f := Frame{
+		Port:    raw[0] >> 4,
+		Command: Command(raw[0] & 0x0F),
+		Data:    raw[1:],
+	}
+	if raw[0] == byte(CMD_RETURN) {
+		f.Port = 0
+		f.Command = CMD_RETURN
+	}
+	return f, nil
+}
+
+// Read the unescaped contents of the next non-empty frame.
+func (d *Decoder) readRaw() ([]byte, error) {
+	// the previous frame is no longer referenced
+	if d.frm != nil {
+		d.buf = d.buf[:0]
+		d.frm = nil
+	}
+
+	for {
+		b, err := d.src.ReadByte()
+		if err != nil {
+			// a partial frame is discarded at the end of the stream
+			return nil, err
+		}
+
+		if b == FEND {
+			frm, err := d.endFrame()
+			if err != nil || frm != nil {
+				return frm, err
+			}
+			continue
+		}
+
+		if !d.inFrame || d.failed != nil {
+			continue
+		}
+
+		if d.escaped {
+			d.escaped = false
+			switch b {
+			case TFEND:
+				b = FEND
+			case TFESC:
+				b = FESC
+			default:
+				d.failed = fmt.Errorf("%w: invalid escape sequence % x", satcom.ErrDecodeFailure, []byte{FESC, b})
+				continue
+			}
+		} else if b == FESC {
+			d.escaped = true
+			continue
+		}
+
+		if len(d.buf) >= MAX_FRAME_SIZE {
+			d.failed = fmt.Errorf("%w: frame exceeds max size %d", satcom.ErrDecodeFailure, MAX_FRAME_SIZE)
+			continue
+		}
+		d.buf = append(d.buf, b)
+	}
+}
+
+// Handle a FEND, returning the frame it completes, if any. Every FEND
+// also begins a new frame.
+func (d *Decoder) endFrame() ([]byte, error) {
+	wasInFrame := d.inFrame
+	failed := d.failed
+	escaped := d.escaped
+
+	d.inFrame = true
+	d.failed = nil
+	d.escaped = false
+
+	if !wasInFrame {
+		return nil, nil
+	}
+
+	if failed == nil && escaped {
+		failed = fmt.Errorf("%w: frame ends with escape", satcom.ErrDecodeFailure)
+	}
+	if failed != nil {
+		d.buf = d.buf[:0]
+		return nil, failed
+	}
+
+	if len(d.buf) == 0 {
+		return nil, nil
+	}
+
+	d.frm = d.buf
+	return d.frm, nil
+}
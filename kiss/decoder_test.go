@@ -0,0 +1,100 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package kiss
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"testing/iotest"
+
+	satcom "github.com/antaris-inc/go-satcom"
+)
+
+func TestDecoder(t *testing.T) {
+	input := []byte{
+		0x01, 0x02, // leading noise
+		FEND, 0x00, 0x01, 0x02, FEND, // data frame
+		FEND, FEND, // empty frames
+		0x10, FESC, TFEND, FESC, TFESC, FEND, // escaped data on port 1
+		0x00, 0x03, FESC, 0x99, 0x04, FEND, // invalid escape
+		0x31, 0x32, FEND, // TXDELAY on port 3
+		0xFF, FEND, // return
+		0x00, FESC, FEND, // trailing escape
+		0x00, 0x05, // incomplete frame
+	}
+
+	want := []struct {
+		frm    Frame
+		decErr bool
+	}{
+		{frm: Frame{Port: 0, Command: CMD_DATA, Data: []byte{0x01, 0x02}}},
+		{frm: Frame{Port: 1, Command: CMD_DATA, Data: []byte{FEND, FESC}}},
+		{decErr: true},
+		{frm: Frame{Port: 3, Command: CMD_TXDELAY, Data: []byte{0x32}}},
+		{frm: Frame{Port: 0, Command: CMD_RETURN, Data: []byte{}}},
+		{decErr: true},
+	}
+
+	// the result must not depend on how the source is chunked
+	for _, src := range []io.Reader{
+		bytes.NewReader(input),
+		iotest.OneByteReader(bytes.NewReader(input)),
+	} {
+		dec := NewDecoder(src)
+		for i, tt := range want {
+			got, err := dec.ReadFrame()
+			if tt.decErr {
+				if !errors.Is(err, satcom.ErrDecodeFailure) {
+					t.Errorf("case %d: expected decode failure, got %v", i, err)
+				}
+				continue
+			} else if err != nil {
+				t.Fatalf("case %d: unexpected error: %v", i, err)
+			}
+
+			if !reflect.DeepEqual(tt.frm, got) {
+				t.Errorf("case %d: unexpected result: want=%+v got=%+v", i, tt.frm, got)
+			}
+		}
+
+		if _, err := dec.ReadFrame(); err != io.EOF {
+			t.Errorf("unexpected error: want=%v got=%v", io.EOF, err)
+		}
+	}
+}
+
+func TestDecoder_MaxFrameSize(t *testing.T) {
+	var input bytes.Buffer
+	input.WriteByte(FEND)
+	input.Write(make([]byte, MAX_FRAME_SIZE+1))
+	input.Write([]byte{FEND, 0x00, 0x01, FEND})
+
+	dec := NewDecoder(&input)
+	if _, err := dec.ReadFrame(); !errors.Is(err, satcom.ErrDecodeFailure) {
+		t.Errorf("expected decode failure, got %v", err)
+	}
+
+	// decoding continues from the next frame
+	got, err := dec.ReadFrame()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []byte{0x01}; !reflect.DeepEqual(want, got.Data) {
+		t.Errorf("unexpected result: want=% x got=% x", want, got.Data)
+	}
+}
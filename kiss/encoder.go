@@ -0,0 +1,117 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package kiss
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// Writes KISS frames to a stream, including the commands used to
+// configure a TNC.
+type Encoder struct {
+	dst io.Writer
+	buf []byte
+}
+
+func NewEncoder(dst io.Writer) *Encoder {
+	return &Encoder{dst: dst}
+}
+
+// Encode and write a single frame.
+func (e *Encoder) WriteFrame(f Frame) error {
+	buf, err := AppendEncode(e.buf[:0], f)
+	if err != nil {
+		return err
+	}
+	e.buf = buf
+
+	n, err := e.dst.Write(buf)
+	if err != nil {
+		return err
+	}
+	if n != len(buf) {
+		return errors.New("partial write")
+	}
+	return nil
+}
+
+// Write a data frame to the given port.
+func (e *Encoder) WriteData(port uint8, data []byte) error {
+	return e.WriteFrame(Frame{Port: port, Command: CMD_DATA, Data: data})
+}
+
+// Time parameters are sent in units of 10ms.
+func durationParam(d time.Duration) (byte, error) {
+	n := d / (10 * time.Millisecond)
+	if d < 0 || n > 255 {
+		return 0, errors.New("duration must be between 0 and 2.55s")
+	}
+	return byte(n), nil
+}
+
+// Set the time to wait between keying the transmitter and sending data.
+func (e *Encoder) SetTXDelay(port uint8, d time.Duration) error {
+	v, err := durationParam(d)
+	if err != nil {
+		return err
+	}
+	return e.WriteFrame(Frame{Port: port, Command: CMD_TXDELAY, Data: []byte{v}})
+}
+
+// Set the persistence parameter p used for CSMA, where the probability
+// of transmitting in a given slot is (p+1)/256.
+func (e *Encoder) SetPersistence(port uint8, p uint8) error {
+	return e.WriteFrame(Frame{Port: port, Command: CMD_PERSISTENCE, Data: []byte{p}})
+}
+
+// Set the slot interval used for CSMA.
+func (e *Encoder) SetSlotTime(port uint8, d time.Duration) error {
+	v, err := durationParam(d)
+	if err != nil {
+		return err
+	}
+	return e.WriteFrame(Frame{Port: port, Command: CMD_SLOTTIME, Data: []byte{v}})
+}
+
+// Set the time to hold the transmitter keyed after sending data. This
+// is obsolete, but still supported by some TNCs.
+func (e *Encoder) SetTXTail(port uint8, d time.Duration) error {
+	v, err := durationParam(d)
+	if err != nil {
+		return err
+	}
+	return e.WriteFrame(Frame{Port: port, Command: CMD_TXTAIL, Data: []byte{v}})
+}
+
+// Enable or disable full duplex operation.
+func (e *Encoder) SetFullDuplex(port uint8, enabled bool) error {
+	var v byte
+	if enabled {
+		v = 1
+	}
+	return e.WriteFrame(Frame{Port: port, Command: CMD_FULLDUPLEX, Data: []byte{v}})
+}
+
+// Send a TNC-specific configuration command.
+func (e *Encoder) SetHardware(port uint8, data []byte) error {
+	return e.WriteFrame(Frame{Port: port, Command: CMD_SET_HARDWARE, Data: data})
+}
+
+// Instruct the TNC to exit KISS mode.
+func (e *Encoder) Return() error {
+	return e.WriteFrame(Frame{Command: CMD_RETURN})
+}
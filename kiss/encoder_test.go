@@ -0,0 +1,75 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package kiss
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEncoder_Commands(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+
+	steps := []func() error{
+		func() error { return enc.WriteData(0, []byte{0x01, FEND}) },
+		func() error { return enc.SetTXDelay(1, 500*time.Millisecond) },
+		func() error { return enc.SetPersistence(1, 63) },
+		func() error { return enc.SetSlotTime(1, 100*time.Millisecond) },
+		func() error { return enc.SetTXTail(1, 20*time.Millisecond) },
+		func() error { return enc.SetFullDuplex(2, true) },
+		func() error { return enc.SetHardware(2, []byte{0xAA, 0xBB}) },
+		func() error { return enc.Return() },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+	}
+
+	want := []byte{
+		FEND, 0x00, 0x01, FESC, TFEND, FEND,
+		FEND, 0x11, 50, FEND,
+		FEND, 0x12, 63, FEND,
+		FEND, 0x13, 10, FEND,
+		FEND, 0x14, 2, FEND,
+		FEND, 0x25, 1, FEND,
+		FEND, 0x26, 0xAA, 0xBB, FEND,
+		FEND, 0xFF, FEND,
+	}
+	if !reflect.DeepEqual(want, buf.Bytes()) {
+		t.Errorf("unexpected result: want=% x got=% x", want, buf.Bytes())
+	}
+}
+
+func TestEncoder_Invalid(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+
+	if err := enc.SetTXDelay(0, 3*time.Second); err == nil {
+		t.Errorf("expected error for out of range duration")
+	}
+	if err := enc.SetSlotTime(0, -time.Millisecond); err == nil {
+		t.Errorf("expected error for negative duration")
+	}
+	if err := enc.WriteData(16, []byte{0x01}); err == nil {
+		t.Errorf("expected error for invalid port")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("unexpected write: % x", buf.Bytes())
+	}
+}
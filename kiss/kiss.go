@@ -0,0 +1,106 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package kiss implements the KISS framing protocol used to communicate
+// with TNCs, as well as by the libcsp serial interface. Frames are
+// delimited by FEND bytes, with any FEND or FESC bytes within the frame
+// escaped. The first byte of each frame identifies the TNC port and the
+// command it carries. See http://www.ax25.net/kiss.aspx
+package kiss
+
+import (
+	"errors"
+)
+
+const (
+	FEND  = 0xC0
+	FESC  = 0xDB
+	TFEND = 0xDC
+	TFESC = 0xDD
+
+	// Largest frame accepted by a Decoder, guarding against unbounded
+	// growth when reading a stream without FEND bytes
+	MAX_FRAME_SIZE = 1 << 16
+
+	// Highest port number that may be addressed
+	MAX_PORT = 15
+)
+
+// Identifies the content of a frame.
+type Command uint8
+
+const (
+	CMD_DATA         Command = 0x00
+	CMD_TXDELAY      Command = 0x01
+	CMD_PERSISTENCE  Command = 0x02
+	CMD_SLOTTIME     Command = 0x03
+	CMD_TXTAIL       Command = 0x04
+	CMD_FULLDUPLEX   Command = 0x05
+	CMD_SET_HARDWARE Command = 0x06
+
+	// Instructs the TNC to exit KISS mode. This applies to all ports,
+	// so is encoded as 0xFF regardless of the frame's Port.
+	CMD_RETURN Command = 0xFF
+)
+
+type Frame struct {
+	Port    uint8
+	Command Command
+	Data    []byte
+}
+
+func (f *Frame) Err() error {
+	if f.Port > MAX_PORT {
+		return errors.New("Port must not exceed 15")
+	}
+	if f.Command > 0x0F && f.Command != CMD_RETURN {
+		return errors.New("unrecognized Command")
+	}
+	return nil
+}
+
+// Returns the leading type byte of the frame, combining its
+// port and command.
+func (f *Frame) typeByte() byte {
+	if f.Command == CMD_RETURN {
+		return byte(CMD_RETURN)
+	}
+	return f.Port<<4 | byte(f.Command)
+}
+
+// Append the complete encoded frame, including delimiters, to dst.
+func AppendEncode(dst []byte, f Frame) ([]byte, error) {
+	if err := f.Err(); err != nil {
+		return dst, err
+	}
+
+	dst = append(dst, FEND)
+	dst = appendEscaped(dst, []byte{f.typeByte()})
+	dst = appendEscaped(dst, f.Data)
+	return append(dst, FEND), nil
+}
+
+func appendEscaped(dst []byte, v []byte) []byte {
+	for _, b := range v {
+		switch b {
+		case FEND:
+			dst = append(dst, FESC, TFEND)
+		case FESC:
+			dst = append(dst, FESC, TFESC)
+		default:
+			dst = append(dst, b)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,87 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package kiss
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAppendEncode(t *testing.T) {
+	tests := []struct {
+		input   Frame
+		want    []byte
+		wantErr bool
+	}{
+		// data frame on port 0
+		{
+			input: Frame{Data: []byte{0x01, 0x02}},
+			want:  []byte{FEND, 0x00, 0x01, 0x02, FEND},
+		},
+
+		// special characters are escaped
+		{
+			input: Frame{Port: 1, Data: []byte{FEND, 0x01, FESC, TFEND}},
+			want:  []byte{FEND, 0x10, FESC, TFEND, 0x01, FESC, TFESC, TFEND, FEND},
+		},
+
+		// type byte is escaped too
+		{
+			input: Frame{Port: 12, Data: []byte{0x01}},
+			want:  []byte{FEND, FESC, TFEND, 0x01, FEND},
+		},
+
+		// command on port 3
+		{
+			input: Frame{Port: 3, Command: CMD_TXDELAY, Data: []byte{50}},
+			want:  []byte{FEND, 0x31, 50, FEND},
+		},
+
+		// return ignores port
+		{
+			input: Frame{Port: 3, Command: CMD_RETURN},
+			want:  []byte{FEND, 0xFF, FEND},
+		},
+
+		// invalid port
+		{
+			input:   Frame{Port: 16},
+			wantErr: true,
+		},
+
+		// invalid command
+		{
+			input:   Frame{Command: 0x10},
+			wantErr: true,
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := AppendEncode(nil, tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("case %d: expected error", i)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("case %d: unexpected result: want=% x got=% x", i, tt.want, got)
+		}
+	}
+}
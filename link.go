@@ -0,0 +1,163 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Framing used in each direction of a Link, which may differ, such as
+// when uplink and downlink use different protocols or frame sizes.
+type LinkConfig struct {
+	// Framing applied to outgoing messages
+	Transmit FrameConfig
+
+	// Framing expected of incoming messages
+	Receive FrameConfig
+}
+
+func (cfg *LinkConfig) Err() error {
+	if err := cfg.Transmit.Err(); err != nil {
+		return fmt.Errorf("Transmit: %v", err)
+	}
+	if err := cfg.Receive.Err(); err != nil {
+		return fmt.Errorf("Receive: %v", err)
+	}
+	return nil
+}
+
+// Create a Link transmitting and receiving over a single stream, such as
+// a net.Conn.
+func NewLink(cfg LinkConfig, rw io.ReadWriter) (*Link, error) {
+	return newLink(cfg, rw, rw, closers(rw))
+}
+
+// Create a Link transmitting and receiving over separate streams, such as
+// the distinct uplink and downlink ports exposed by some modems.
+func NewSplitLink(cfg LinkConfig, dst io.Writer, src io.Reader) (*Link, error) {
+	return newLink(cfg, dst, src, closers(dst, src))
+}
+
+// Collect the streams that must be closed along with a Link.
+func closers(streams ...interface{}) []io.Closer {
+	var cs []io.Closer
+	for _, s := range streams {
+		if c, ok := s.(io.Closer); ok {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+func newLink(cfg LinkConfig, dst io.Writer, src io.Reader, cs []io.Closer) (*Link, error) {
+	if err := cfg.Err(); err != nil {
+		return nil, err
+	}
+
+	fs, err := NewFrameSender(cfg.Transmit, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	fr, err := NewFrameReceiver(cfg.Receive, src)
+	if err != nil {
+		return nil, err
+	}
+
+	l := Link{
+		fs:      fs,
+		fr:      fr,
+		closers: cs,
+		closed:  make(chan struct{}),
+	}
+	return &l, nil
+}
+
+// Bidirectional communication channel combining a FrameSender and a
+// FrameReceiver. It is safe to call Send and Recv concurrently.
+type Link struct {
+	fs *FrameSender
+	fr *FrameReceiver
+
+	// Closed along with the Link, if supported
+	closers []io.Closer
+
+	closed    chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// Encode and write a single message.
+func (l *Link) Send(ctx context.Context, msg []byte) error {
+	select {
+	case <-l.closed:
+		return ErrSenderClosed
+	default:
+	}
+	return l.fs.SendContext(ctx, msg)
+}
+
+// Block until the next message is received, returning its payload.
+func (l *Link) Recv(ctx context.Context) ([]byte, error) {
+	frm, err := l.fr.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return frm.Payload, nil
+}
+
+// Block until the next frame is received, including reception metadata.
+func (l *Link) RecvFrame(ctx context.Context) (*Frame, error) {
+	return l.fr.Next(ctx)
+}
+
+// Returns the size of the largest message that may be sent.
+func (l *Link) MaxPayloadSize() int {
+	return l.fs.MaxPayloadSize()
+}
+
+// Close the Link, interrupting any blocked Recv and closing the underlying
+// streams if they implement io.Closer. Subsequent calls to Send return
+// ErrSenderClosed and calls to Recv return ErrReceiverClosed.
+func (l *Link) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.closed)
+		l.fr.Close()
+		for _, c := range l.closers {
+			if err := c.Close(); err != nil && l.closeErr == nil {
+				l.closeErr = err
+			}
+		}
+	})
+	return l.closeErr
+}
+
+// Counters describing the activity of both directions of a Link.
+type LinkStats struct {
+	Transmit SenderStats
+	Receive  ReceiverStats
+}
+
+// Returns a snapshot of the link counters. This is safe to call
+// concurrently with Send and Recv.
+func (l *Link) Stats() LinkStats {
+	return LinkStats{
+		Transmit: l.fs.Stats(),
+		Receive:  l.fr.Stats(),
+	}
+}
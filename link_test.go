@@ -0,0 +1,204 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/antaris-inc/go-satcom/satlab"
+)
+
+func makeLinkTestConfig() LinkConfig {
+	return LinkConfig{
+		// fixed-size Spaceframes
+		Transmit: FrameConfig{
+			FrameSyncMarker: satlab.SATLAB_ASM,
+			FrameSize:       223,
+			Adapters:        makeSpaceframeStack(217),
+		},
+
+		// variable-size frames with a leading length byte
+		Receive: FrameConfig{
+			FrameSyncMarker: []byte{0xD3, 0x91},
+			FrameSize:       32,
+			LengthField:     &FrameLengthField{Width: 1, Adjustment: 1},
+		},
+	}
+}
+
+func TestLinkConfig_Err(t *testing.T) {
+	valid := makeLinkTestConfig()
+
+	tests := []struct {
+		cfg     LinkConfig
+		wantErr bool
+	}{
+		{
+			cfg:     valid,
+			wantErr: false,
+		},
+		{
+			cfg:     LinkConfig{Transmit: valid.Transmit},
+			wantErr: true,
+		},
+		{
+			cfg:     LinkConfig{Receive: valid.Receive},
+			wantErr: true,
+		},
+	}
+
+	for i, tt := range tests {
+		err := tt.cfg.Err()
+		if tt.wantErr != (err != nil) {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+		}
+	}
+}
+
+func TestLink_Asymmetric(t *testing.T) {
+	groundConn, spaceConn := net.Pipe()
+
+	groundCfg := makeLinkTestConfig()
+	ground, err := NewLink(groundCfg, groundConn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ground.Close()
+
+	// the spacecraft uses the opposite configuration
+	spaceCfg := LinkConfig{
+		Transmit: groundCfg.Receive,
+		Receive:  groundCfg.Transmit,
+	}
+	space, err := NewLink(spaceCfg, spaceConn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer space.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	uplink := []byte("COMMAND")
+	downlink := []byte{0x05, 0x01, 0x02, 0x03, 0x04, 0x05}
+
+	sendErrC := make(chan error, 2)
+	go func() { sendErrC <- ground.Send(ctx, uplink) }()
+	go func() { sendErrC <- space.Send(ctx, downlink) }()
+
+	got, err := space.Recv(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(uplink, got) {
+		t.Errorf("unexpected uplink: want=% x got=% x", uplink, got)
+	}
+
+	frm, err := ground.RecvFrame(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(downlink, frm.Payload) {
+		t.Errorf("unexpected downlink: want=% x got=% x", downlink, frm.Payload)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := <-sendErrC; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	st := ground.Stats()
+	if st.Transmit.FramesSent != 1 || st.Receive.FramesReceived != 1 {
+		t.Errorf("unexpected stats: %+v", st)
+	}
+
+	if got := ground.MaxPayloadSize(); got != 217 {
+		t.Errorf("unexpected max payload size: want=217 got=%d", got)
+	}
+}
+
+func TestLink_Close(t *testing.T) {
+	conn, peer := net.Pipe()
+	defer peer.Close()
+
+	l, err := NewLink(makeLinkTestConfig(), conn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errC := make(chan error, 1)
+	go func() {
+		_, err := l.Recv(context.Background())
+		errC <- err
+	}()
+
+	// allow Recv to block
+	time.Sleep(10 * time.Millisecond)
+
+	if err := l.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case err := <-errC:
+		if err != ErrReceiverClosed {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Recv not interrupted by Close")
+	}
+
+	if err := l.Send(context.Background(), []byte{0x01}); err != ErrSenderClosed {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// the underlying stream is closed too
+	if _, err := peer.Write([]byte{0x01}); err == nil {
+		t.Errorf("expected non-nil error")
+	}
+}
+
+func TestNewSplitLink(t *testing.T) {
+	cfg := makeLinkTestConfig()
+
+	src := bytes.NewBuffer([]byte{0xD3, 0x91, 0x02, 0xAA, 0xBB})
+	dst := bytes.NewBuffer(nil)
+
+	l, err := NewSplitLink(cfg, dst, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := l.Send(context.Background(), []byte{0x01}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := dst.Len(); got != 227 {
+		t.Errorf("unexpected output length: want=227 got=%d", got)
+	}
+
+	got, err := l.Recv(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []byte{0x02, 0xAA, 0xBB}; !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=% x got=% x", want, got)
+	}
+}
@@ -0,0 +1,267 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// Pseudo address identifying one end of a satcom Link, such as a ground
+// station or spacecraft.
+type LinkAddr struct {
+	Name string
+}
+
+func (a LinkAddr) Network() string {
+	return "satcom"
+}
+
+func (a LinkAddr) String() string {
+	return a.Name
+}
+
+// Create a net.PacketConn exchanging datagrams over the provided Link, each
+// carried in a single frame. The PacketConn takes ownership of the Link.
+func NewPacketConn(l *Link, local, remote LinkAddr) *PacketConn {
+	return &PacketConn{
+		link:          l,
+		local:         local,
+		remote:        remote,
+		readDeadline:  makeDeadline(),
+		writeDeadline: makeDeadline(),
+		closed:        make(chan struct{}),
+	}
+}
+
+// Implements net.PacketConn over a satcom Link, allowing existing software
+// built around UDP-style datagrams to run unmodified. As a Link is point to
+// point, all datagrams are received from, and sent to, the remote address
+// regardless of the address passed to WriteTo.
+type PacketConn struct {
+	link   *Link
+	local  LinkAddr
+	remote LinkAddr
+
+	readDeadline  deadline
+	writeDeadline deadline
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+var _ net.PacketConn = (*PacketConn)(nil)
+
+// Read a single datagram into p. As with UDP, datagrams larger than p are
+// truncated, and those that cannot be decoded are silently dropped. Such
+// failures are still counted in the Link stats.
+func (c *PacketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	ctx, cancel, err := c.opContext(&c.readDeadline)
+	if err != nil {
+		return 0, nil, c.opError("read", err)
+	}
+	defer cancel()
+
+	for {
+		msg, err := c.link.Recv(ctx)
+		if errors.Is(err, ErrDecodeFailure) {
+			continue
+		} else if err != nil {
+			return 0, nil, c.opError("read", c.translateError(&c.readDeadline, err))
+		}
+
+		return copy(p, msg), c.remote, nil
+	}
+}
+
+// Write a single datagram, which must fit within a single frame.
+func (c *PacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	if len(p) > c.link.MaxPayloadSize() {
+		return 0, c.opError("write", errors.New("message too long"))
+	}
+
+	ctx, cancel, err := c.opContext(&c.writeDeadline)
+	if err != nil {
+		return 0, c.opError("write", err)
+	}
+	defer cancel()
+
+	if err := c.link.Send(ctx, p); err != nil {
+		return 0, c.opError("write", c.translateError(&c.writeDeadline, err))
+	}
+
+	return len(p), nil
+}
+
+// Build a context that is cancelled when the given deadline expires or the
+// PacketConn is closed.
+func (c *PacketConn) opContext(d *deadline) (context.Context, context.CancelFunc, error) {
+	expired := d.wait()
+
+	select {
+	case <-c.closed:
+		return nil, nil, net.ErrClosed
+	case <-expired:
+		return nil, nil, os.ErrDeadlineExceeded
+	default:
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-expired:
+			cancel()
+		case <-c.closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel, nil
+}
+
+// Describe an interrupted operation using the errors expected of a net.Conn.
+func (c *PacketConn) translateError(d *deadline, err error) error {
+	select {
+	case <-c.closed:
+		return net.ErrClosed
+	default:
+	}
+
+	if isInterrupt(err) {
+		select {
+		case <-d.wait():
+			return os.ErrDeadlineExceeded
+		default:
+		}
+	}
+
+	return err
+}
+
+func (c *PacketConn) opError(op string, err error) error {
+	return &net.OpError{
+		Op:     op,
+		Net:    c.local.Network(),
+		Source: c.local,
+		Addr:   c.remote,
+		Err:    err,
+	}
+}
+
+// Close the PacketConn and its underlying Link, unblocking any pending
+// ReadFrom or WriteTo.
+func (c *PacketConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		err = c.link.Close()
+	})
+	return err
+}
+
+func (c *PacketConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+// Returns the address of the far end of the Link.
+func (c *PacketConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *PacketConn) SetDeadline(t time.Time) error {
+	c.readDeadline.set(t)
+	c.writeDeadline.set(t)
+	return nil
+}
+
+func (c *PacketConn) SetReadDeadline(t time.Time) error {
+	c.readDeadline.set(t)
+	return nil
+}
+
+func (c *PacketConn) SetWriteDeadline(t time.Time) error {
+	c.writeDeadline.set(t)
+	return nil
+}
+
+func makeDeadline() deadline {
+	return deadline{cancel: make(chan struct{})}
+}
+
+// An I/O deadline that may be changed while an operation is blocked,
+// following the approach of net.Pipe.
+type deadline struct {
+	mu     sync.Mutex
+	timer  *time.Timer
+	cancel chan struct{} // closed once the deadline expires
+}
+
+func (d *deadline) set(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// wait for a running timer to close the channel
+	if d.timer != nil && !d.timer.Stop() {
+		<-d.cancel
+	}
+	d.timer = nil
+
+	expired := isClosedChan(d.cancel)
+
+	// a zero time clears the deadline
+	if t.IsZero() {
+		if expired {
+			d.cancel = make(chan struct{})
+		}
+		return
+	}
+
+	if dur := time.Until(t); dur > 0 {
+		if expired {
+			d.cancel = make(chan struct{})
+		}
+		cancel := d.cancel
+		d.timer = time.AfterFunc(dur, func() {
+			close(cancel)
+		})
+		return
+	}
+
+	// deadline is in the past
+	if !expired {
+		close(d.cancel)
+	}
+}
+
+// Returns a channel that is closed once the deadline expires.
+func (d *deadline) wait() chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.cancel
+}
+
+func isClosedChan(c <-chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
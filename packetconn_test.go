@@ -0,0 +1,232 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/antaris-inc/go-satcom/crc"
+	"github.com/sigurn/crc16"
+)
+
+// Create a pair of PacketConns connected by an in-memory stream.
+func makePacketConnPair(t *testing.T) (*PacketConn, *PacketConn) {
+	groundConn, spaceConn := net.Pipe()
+
+	cfg := makeLinkTestConfig()
+	ground, err := NewLink(cfg, groundConn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	space, err := NewLink(LinkConfig{Transmit: cfg.Receive, Receive: cfg.Transmit}, spaceConn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	gs := LinkAddr{Name: "ground"}
+	sc := LinkAddr{Name: "spacecraft"}
+	return NewPacketConn(ground, gs, sc), NewPacketConn(space, sc, gs)
+}
+
+func TestPacketConn(t *testing.T) {
+	ground, space := makePacketConnPair(t)
+	defer ground.Close()
+	defer space.Close()
+
+	errC := make(chan error, 1)
+	go func() {
+		_, err := ground.WriteTo([]byte("PING"), &net.UDPAddr{})
+		errC <- err
+	}()
+
+	buf := make([]byte, 64)
+	n, addr, err := space.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := buf[:n]; !reflect.DeepEqual([]byte("PING"), got) {
+		t.Errorf("unexpected result: % x", got)
+	}
+	if want := (LinkAddr{Name: "ground"}); addr != want {
+		t.Errorf("unexpected address: want=%v got=%v", want, addr)
+	}
+	if err := <-errC; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// reply using the address from ReadFrom
+	go func() {
+		_, err := space.WriteTo([]byte{0x04, 0x50, 0x4F, 0x4E, 0x47}, addr)
+		errC <- err
+	}()
+
+	// datagrams are truncated to fit the buffer
+	n, _, err = ground.ReadFrom(buf[:3])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := buf[:n], []byte{0x04, 0x50, 0x4F}; !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=% x got=% x", want, got)
+	}
+	if err := <-errC; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if addr := ground.LocalAddr(); addr.Network() != "satcom" || addr.String() != "ground" {
+		t.Errorf("unexpected local address: %v", addr)
+	}
+}
+
+func TestPacketConn_ReadDecodeFailure(t *testing.T) {
+	crc16Adapter, _ := crc.NewCRC16Adapter(crc.CRC16AdapterConfig{
+		Algorithm: crc16.CRC16_CCITT_FALSE,
+	})
+	fcfg := FrameConfig{
+		FrameSyncMarker: []byte{0xD3, 0x91},
+		FrameSize:       6,
+		Adapters:        []Adapter{crc16Adapter},
+	}
+
+	var buf bytes.Buffer
+	fs, err := NewFrameSender(fcfg, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, msg := range [][]byte{[]byte("BAD!"), []byte("GOOD")} {
+		if err := fs.Send(msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// corrupt the payload of the first frame
+	stream := buf.Bytes()
+	stream[2] ^= 0x01
+
+	l, err := NewSplitLink(LinkConfig{Transmit: fcfg, Receive: fcfg}, io.Discard, bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pc := NewPacketConn(l, LinkAddr{Name: "ground"}, LinkAddr{Name: "spacecraft"})
+	defer pc.Close()
+
+	// the corrupted datagram is dropped, as with UDP
+	p := make([]byte, 64)
+	n, _, err := pc.ReadFrom(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := p[:n]; !reflect.DeepEqual([]byte("GOOD"), got) {
+		t.Errorf("unexpected result: % x", got)
+	}
+
+	if stats := l.Stats(); stats.Receive.AdapterErrors[0] != 1 {
+		t.Errorf("unexpected stats: %+v", stats.Receive)
+	}
+
+	if _, _, err := pc.ReadFrom(p); err == nil || errors.Is(err, ErrDecodeFailure) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestPacketConn_ReadDeadline(t *testing.T) {
+	ground, space := makePacketConnPair(t)
+	defer ground.Close()
+	defer space.Close()
+
+	// deadline already expired
+	ground.SetReadDeadline(time.Now().Add(-time.Second))
+	if _, _, err := ground.ReadFrom(make([]byte, 8)); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// deadline set while blocked
+	ground.SetReadDeadline(time.Time{})
+	errC := make(chan error, 1)
+	go func() {
+		_, _, err := ground.ReadFrom(make([]byte, 8))
+		errC <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	ground.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+
+	select {
+	case err := <-errC:
+		var nerr net.Error
+		if !errors.As(err, &nerr) || !nerr.Timeout() {
+			t.Errorf("expected timeout error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("ReadFrom not interrupted by deadline")
+	}
+}
+
+func TestPacketConn_WriteDeadline(t *testing.T) {
+	ground, space := makePacketConnPair(t)
+	defer ground.Close()
+	defer space.Close()
+
+	// nothing reads from the far end, so the write blocks
+	ground.SetWriteDeadline(time.Now().Add(10 * time.Millisecond))
+	if _, err := ground.WriteTo([]byte{0x01}, nil); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPacketConn_WriteTooLarge(t *testing.T) {
+	ground, space := makePacketConnPair(t)
+	defer ground.Close()
+	defer space.Close()
+
+	if _, err := ground.WriteTo(make([]byte, 218), nil); err == nil {
+		t.Fatalf("expected non-nil error")
+	}
+}
+
+func TestPacketConn_Close(t *testing.T) {
+	ground, space := makePacketConnPair(t)
+	defer space.Close()
+
+	errC := make(chan error, 1)
+	go func() {
+		_, _, err := ground.ReadFrom(make([]byte, 8))
+		errC <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	if err := ground.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case err := <-errC:
+		if !errors.Is(err, net.ErrClosed) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("ReadFrom not interrupted by Close")
+	}
+
+	if _, err := ground.WriteTo([]byte{0x01}, nil); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
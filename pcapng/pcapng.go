@@ -0,0 +1,77 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package pcapng reads and writes captures in the pcapng format, allowing
+// frames sent and received over a satcom link to be inspected using tools
+// such as Wireshark. See https://www.ietf.org/archive/id/draft-ietf-opsawg-pcapng-02.html
+package pcapng
+
+import (
+	"time"
+)
+
+// Link-layer header type, describing the format of captured packets.
+// See https://www.tcpdump.org/linktypes.html
+type LinkType uint16
+
+const (
+	LINKTYPE_AX25      LinkType = 3
+	LINKTYPE_USER0     LinkType = 147
+	LINKTYPE_USER1     LinkType = 148
+	LINKTYPE_USER2     LinkType = 149
+	LINKTYPE_AX25_KISS LinkType = 202
+)
+
+// Direction of a captured packet, relative to the capturing interface.
+type Direction uint8
+
+const (
+	DIRECTION_UNKNOWN  Direction = 0
+	DIRECTION_INBOUND  Direction = 1
+	DIRECTION_OUTBOUND Direction = 2
+)
+
+// A single captured packet.
+type Packet struct {
+	Timestamp time.Time
+	LinkType  LinkType
+	Direction Direction
+	Data      []byte
+
+	// Index of the interface the packet was captured on within
+	// its section. This is set by Reader, and ignored by Writer,
+	// which uses a separate interface for each LinkType.
+	Interface int
+}
+
+const (
+	BLOCK_TYPE_SECTION_HEADER      = 0x0A0D0D0A
+	BLOCK_TYPE_INTERFACE           = 0x00000001
+	BLOCK_TYPE_SIMPLE_PACKET       = 0x00000003
+	BLOCK_TYPE_ENHANCED_PACKET     = 0x00000006
+	BYTE_ORDER_MAGIC               = 0x1A2B3C4D
+	OPTION_END_OF_OPTIONS          = 0
+	OPTION_SHB_USER_APPLICATION    = 4
+	OPTION_IF_TIMESTAMP_RESOLUTION = 9
+	OPTION_EPB_FLAGS               = 2
+
+	// Largest block accepted when reading a capture, guarding
+	// against huge allocations when reading a corrupt file
+	MAX_BLOCK_SIZE = 1 << 24
+)
+
+// Round the provided length up to a multiple of 4 bytes.
+func pad4(n int) int {
+	return (n + 3) &^ 3
+}
@@ -0,0 +1,302 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package pcapng
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"time"
+)
+
+// Describes an interface declared within the current section.
+type readerInterface struct {
+	linkType LinkType
+	snapLen  uint32
+
+	// timestamp resolution, as encoded in the if_tsresol option
+	tsresol uint8
+}
+
+// Reads packets from a pcapng capture. Sections of either byte order
+// are supported, along with enhanced and simple packet blocks. All other
+// blocks are skipped.
+type Reader struct {
+	src io.Reader
+
+	// byte order of the current section, nil until a section
+	// header has been read
+	order binary.ByteOrder
+
+	interfaces []readerInterface
+
+	buf []byte
+}
+
+func NewReader(src io.Reader) *Reader {
+	return &Reader{src: src}
+}
+
+// Return the next packet from the capture, or io.EOF once all packets
+// have been read. The returned data is only valid until the next call.
+func (r *Reader) Next() (Packet, error) {
+	for {
+		typ, body, err := r.readBlock()
+		if err != nil {
+			return Packet{}, err
+		}
+
+		switch typ {
+		case BLOCK_TYPE_INTERFACE:
+			if err := r.readInterface(body); err != nil {
+				return Packet{}, err
+			}
+		case BLOCK_TYPE_ENHANCED_PACKET:
+			return r.readEnhancedPacket(body)
+		case BLOCK_TYPE_SIMPLE_PACKET:
+			return r.readSimplePacket(body)
+		}
+	}
+}
+
+// Read the next block, returning its type and body. Section headers are
+// processed here, as they determine how all other blocks are read.
+func (r *Reader) readBlock() (uint32, []byte, error) {
+	var hdr [12]byte
+	if _, err := io.ReadFull(r.src, hdr[:8]); err != nil {
+		return 0, nil, err
+	}
+
+	typ := binary.LittleEndian.Uint32(hdr[:4])
+
+	if typ == BLOCK_TYPE_SECTION_HEADER {
+		if _, err := io.ReadFull(r.src, hdr[8:12]); err != nil {
+			return 0, nil, unexpectedEOF(err)
+		}
+		if binary.LittleEndian.Uint32(hdr[8:12]) == BYTE_ORDER_MAGIC {
+			r.order = binary.LittleEndian
+		} else if binary.BigEndian.Uint32(hdr[8:12]) == BYTE_ORDER_MAGIC {
+			r.order = binary.BigEndian
+		} else {
+			return 0, nil, errors.New("invalid byte order magic")
+		}
+		r.interfaces = r.interfaces[:0]
+	} else if r.order == nil {
+		return 0, nil, errors.New("capture does not begin with a section header")
+	} else {
+		typ = r.order.Uint32(hdr[:4])
+	}
+
+	n := int(r.order.Uint32(hdr[4:8]))
+	if n < 12 || n%4 != 0 {
+		return 0, nil, fmt.Errorf("invalid block length %d", n)
+	}
+	if n > MAX_BLOCK_SIZE {
+		return 0, nil, errors.New("block exceeds max size")
+	}
+
+	// the remainder of the block, following the length field
+	if cap(r.buf) < n-8 {
+		r.buf = make([]byte, n-8)
+	}
+	buf := r.buf[:n-8]
+
+	pre := 0
+	if typ == BLOCK_TYPE_SECTION_HEADER {
+		pre = copy(buf, hdr[8:12])
+	}
+	if _, err := io.ReadFull(r.src, buf[pre:]); err != nil {
+		return 0, nil, unexpectedEOF(err)
+	}
+
+	if trailer := int(r.order.Uint32(buf[len(buf)-4:])); trailer != n {
+		return 0, nil, errors.New("block length mismatch")
+	}
+
+	return typ, buf[:len(buf)-4], nil
+}
+
+func (r *Reader) readInterface(body []byte) error {
+	if len(body) < 8 {
+		return errors.New("interface description block too short")
+	}
+
+	iface := readerInterface{
+		linkType: LinkType(r.order.Uint16(body[0:2])),
+		snapLen:  r.order.Uint32(body[4:8]),
+		tsresol:  6,
+	}
+
+	err := r.readOptions(body[8:], func(code uint16, value []byte) {
+		if code == OPTION_IF_TIMESTAMP_RESOLUTION && len(value) == 1 {
+			iface.tsresol = value[0]
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	r.interfaces = append(r.interfaces, iface)
+	return nil
+}
+
+func (r *Reader) readEnhancedPacket(body []byte) (Packet, error) {
+	if len(body) < 20 {
+		return Packet{}, errors.New("enhanced packet block too short")
+	}
+
+	idx := int(r.order.Uint32(body[0:4]))
+	if idx >= len(r.interfaces) {
+		return Packet{}, fmt.Errorf("packet references unknown interface %d", idx)
+	}
+	iface := r.interfaces[idx]
+
+	ts := uint64(r.order.Uint32(body[4:8]))<<32 | uint64(r.order.Uint32(body[8:12]))
+	capN := int(r.order.Uint32(body[12:16]))
+	if 20+pad4(capN) > len(body) {
+		return Packet{}, errors.New("captured length exceeds block")
+	}
+
+	p := Packet{
+		Timestamp: timestamp(ts, iface.tsresol),
+		LinkType:  iface.linkType,
+		Data:      body[20 : 20+capN],
+		Interface: idx,
+	}
+
+	err := r.readOptions(body[20+pad4(capN):], func(code uint16, value []byte) {
+		if code == OPTION_EPB_FLAGS && len(value) == 4 {
+			p.Direction = Direction(r.order.Uint32(value) & 0x3)
+		}
+	})
+	if err != nil {
+		return Packet{}, err
+	}
+
+	return p, nil
+}
+
+// Simple packets carry no timestamp, and are captured on the first
+// interface of the section.
+func (r *Reader) readSimplePacket(body []byte) (Packet, error) {
+	if len(r.interfaces) == 0 {
+		return Packet{}, errors.New("packet references unknown interface 0")
+	}
+	if len(body) < 4 {
+		return Packet{}, errors.New("simple packet block too short")
+	}
+	iface := r.interfaces[0]
+
+	capN := int(r.order.Uint32(body[0:4]))
+	if iface.snapLen > 0 && capN > int(iface.snapLen) {
+		capN = int(iface.snapLen)
+	}
+	if 4+capN > len(body) {
+		return Packet{}, errors.New("captured length exceeds block")
+	}
+
+	p := Packet{
+		LinkType: iface.linkType,
+		Data:     body[4 : 4+capN],
+	}
+	return p, nil
+}
+
+// Pass each option in the provided data to fn, stopping at the end
+// of options marker.
+func (r *Reader) readOptions(data []byte, fn func(code uint16, value []byte)) error {
+	for len(data) >= 4 {
+		code := r.order.Uint16(data[0:2])
+		n := int(r.order.Uint16(data[2:4]))
+		if code == OPTION_END_OF_OPTIONS {
+			return nil
+		}
+		if 4+pad4(n) > len(data) {
+			return errors.New("option exceeds block")
+		}
+		fn(code, data[4:4+n])
+		data = data[4+pad4(n):]
+	}
+	return nil
+}
+
+// Convert a timestamp to time.Time, given the resolution described by
+// an if_tsresol option: a negative power of 10, or of 2 if the most
+// significant bit is set.
+func timestamp(ts uint64, tsresol uint8) time.Time {
+	exp := uint(tsresol & 0x7F)
+
+	if tsresol&0x80 != 0 {
+		if exp >= 64 {
+			return time.Unix(0, 0)
+		}
+		sec := ts >> exp
+		frac := ts & (1<<exp - 1)
+		hi, lo := bits.Mul64(frac, uint64(time.Second))
+		nsec, _ := bits.Div64(hi, lo, 1<<exp)
+		return time.Unix(int64(sec), int64(nsec))
+	}
+
+	// resolutions finer than 10^-19 cannot be represented
+	var unit uint64 = 1
+	for i := uint(0); i < exp && i < 19; i++ {
+		unit *= 10
+	}
+	sec := ts / unit
+	frac := ts % unit
+	hi, lo := bits.Mul64(frac, uint64(time.Second))
+	nsec, _ := bits.Div64(hi, lo, unit)
+	return time.Unix(int64(sec), int64(nsec))
+}
+
+// A capture ending part way through a block is truncated.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Provides an io.Reader returning the data of each packet in a capture
+// that satisfies the provided filter, such that a capture of raw frames
+// may be fed back into a satcom.FrameReceiver. All packets are included
+// if filter is nil.
+func NewStream(r *Reader, filter func(Packet) bool) io.Reader {
+	return &stream{r: r, filter: filter}
+}
+
+type stream struct {
+	r       *Reader
+	filter  func(Packet) bool
+	pending []byte
+}
+
+func (s *stream) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		pkt, err := s.r.Next()
+		if err != nil {
+			return 0, err
+		}
+		if s.filter == nil || s.filter(pkt) {
+			s.pending = pkt.Data
+		}
+	}
+
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,178 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package pcapng
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Builds big-endian captures, to exercise paths not used by Writer.
+type bigEndianCapture struct {
+	bytes.Buffer
+}
+
+func (c *bigEndianCapture) block(typ uint32, body []byte) {
+	n := uint32(12 + pad4(len(body)))
+	c.Write(binary.BigEndian.AppendUint32(nil, typ))
+	c.Write(binary.BigEndian.AppendUint32(nil, n))
+	c.Write(body)
+	c.Write(make([]byte, pad4(len(body))-len(body)))
+	c.Write(binary.BigEndian.AppendUint32(nil, n))
+}
+
+func (c *bigEndianCapture) sectionHeader() {
+	body := binary.BigEndian.AppendUint32(nil, BYTE_ORDER_MAGIC)
+	body = append(body, 0x00, 0x01, 0x00, 0x00)
+	body = append(body, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF)
+	c.block(BLOCK_TYPE_SECTION_HEADER, body)
+}
+
+func (c *bigEndianCapture) iface(lt LinkType, snapLen uint32, tsresol []byte) {
+	body := binary.BigEndian.AppendUint16(nil, uint16(lt))
+	body = append(body, 0x00, 0x00)
+	body = binary.BigEndian.AppendUint32(body, snapLen)
+	if tsresol != nil {
+		body = append(body, 0x00, 0x09, 0x00, 0x01, tsresol[0], 0x00, 0x00, 0x00)
+		body = append(body, 0x00, 0x00, 0x00, 0x00)
+	}
+	c.block(BLOCK_TYPE_INTERFACE, body)
+}
+
+func (c *bigEndianCapture) enhancedPacket(iface uint32, ts uint64, data []byte, flags []byte) {
+	body := binary.BigEndian.AppendUint32(nil, iface)
+	body = binary.BigEndian.AppendUint32(body, uint32(ts>>32))
+	body = binary.BigEndian.AppendUint32(body, uint32(ts))
+	body = binary.BigEndian.AppendUint32(body, uint32(len(data)))
+	body = binary.BigEndian.AppendUint32(body, uint32(len(data)))
+	body = append(body, data...)
+	body = append(body, make([]byte, pad4(len(data))-len(data))...)
+	if flags != nil {
+		body = append(body, 0x00, 0x02, 0x00, 0x04)
+		body = append(body, flags...)
+		body = append(body, 0x00, 0x00, 0x00, 0x00)
+	}
+	c.block(BLOCK_TYPE_ENHANCED_PACKET, body)
+}
+
+func TestReader_BigEndian(t *testing.T) {
+	var c bigEndianCapture
+	c.sectionHeader()
+	c.iface(LINKTYPE_AX25, 0, nil)                // default microsecond resolution
+	c.iface(LINKTYPE_USER1, 2, []byte{0x83})      // 1/8 second resolution
+	c.block(0x00000BAD, []byte{0x01, 0x02, 0x03}) // unrecognized, skipped
+	c.enhancedPacket(0, 1700000000123456, []byte{0x01, 0x02, 0x03}, []byte{0x00, 0x00, 0x00, 0x02})
+	c.enhancedPacket(1, 13, []byte{0x04}, nil)
+
+	// simple packet, truncated to the snap length of the first interface
+	c.block(BLOCK_TYPE_SIMPLE_PACKET, []byte{0x00, 0x00, 0x00, 0x02, 0x05, 0x06})
+
+	want := []Packet{
+		{
+			Timestamp: time.Unix(1700000000, 123456000),
+			LinkType:  LINKTYPE_AX25,
+			Direction: DIRECTION_OUTBOUND,
+			Data:      []byte{0x01, 0x02, 0x03},
+		},
+		{
+			Timestamp: time.Unix(1, 625000000),
+			LinkType:  LINKTYPE_USER1,
+			Data:      []byte{0x04},
+			Interface: 1,
+		},
+		{
+			LinkType: LINKTYPE_AX25,
+			Data:     []byte{0x05, 0x06},
+		},
+	}
+
+	r := NewReader(&c)
+	for i := range want {
+		got, err := r.Next()
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+		if !got.Timestamp.Equal(want[i].Timestamp) {
+			t.Errorf("case %d: unexpected timestamp: want=%v got=%v", i, want[i].Timestamp, got.Timestamp)
+		}
+		got.Timestamp = want[i].Timestamp
+		if !reflect.DeepEqual(want[i], got) {
+			t.Errorf("case %d: unexpected result: want=%+v got=%+v", i, want[i], got)
+		}
+	}
+
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("unexpected error: want=%v got=%v", io.EOF, err)
+	}
+}
+
+func TestReader_Errors(t *testing.T) {
+	var valid bytes.Buffer
+	if err := NewWriter(&valid).WritePacket(Packet{Data: []byte{0x01, 0x02}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var noSection bigEndianCapture
+	noSection.iface(LINKTYPE_USER0, 0, nil)
+
+	var noInterface bigEndianCapture
+	noInterface.sectionHeader()
+	noInterface.enhancedPacket(0, 0, []byte{0x01}, nil)
+
+	badMagic := append([]byte{}, valid.Bytes()...)
+	badMagic[8] = 0x00
+
+	badTrailer := append([]byte{}, valid.Bytes()...)
+	badTrailer[len(badTrailer)-1] = 0x01
+
+	tests := [][]byte{
+		noSection.Bytes(),
+		noInterface.Bytes(),
+		badMagic,
+		badTrailer,
+		valid.Bytes()[:valid.Len()-4],
+	}
+
+	for i, input := range tests {
+		if _, err := NewReader(bytes.NewReader(input)).Next(); err == nil || err == io.EOF {
+			t.Errorf("case %d: expected error, got %v", i, err)
+		}
+	}
+}
+
+func TestNewStream(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.WritePacket(Packet{Direction: DIRECTION_INBOUND, Data: []byte{0x01, 0x02}})
+	w.WritePacket(Packet{Direction: DIRECTION_OUTBOUND, Data: []byte{0x03}})
+	w.WritePacket(Packet{Direction: DIRECTION_INBOUND, Data: []byte{0x04, 0x05}})
+
+	s := NewStream(NewReader(&buf), func(p Packet) bool {
+		return p.Direction == DIRECTION_INBOUND
+	})
+	got, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []byte{0x01, 0x02, 0x04, 0x05}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=% x got=% x", want, got)
+	}
+}
@@ -0,0 +1,129 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package pcapng
+
+import (
+	"errors"
+	"sync"
+
+	satcom "github.com/antaris-inc/go-satcom"
+)
+
+// Identifies the portion of each frame recorded by a Recorder.
+type Layer int
+
+const (
+	// Complete frame, including the sync marker
+	LAYER_RAW Layer = iota
+
+	// Message decoded from the frame by the configured Adapters
+	LAYER_PAYLOAD
+)
+
+// Returns the link type conventionally used to record the given layer.
+// As Wireshark has no link type for these layers, each is recorded using
+// one of the USER link types, for which a dissector may be configured.
+func (l Layer) LinkType() LinkType {
+	switch l {
+	case LAYER_PAYLOAD:
+		return LINKTYPE_USER1
+	default:
+		return LINKTYPE_USER0
+	}
+}
+
+type RecorderConfig struct {
+	Layer Layer
+
+	// Link type recorded with each packet, defaulting to that
+	// returned by Layer.LinkType. Set this to describe the content
+	// of a layer, such as LINKTYPE_AX25 for decoded AX.25 frames, or
+	// a USER link type with a "csp" dissector configured for the CSP
+	// packets carried by a Satlab SRS4.
+	LinkType LinkType
+}
+
+func (cfg *RecorderConfig) Err() error {
+	if cfg.Layer < LAYER_RAW || cfg.Layer > LAYER_PAYLOAD {
+		return errors.New("unrecognized Layer")
+	}
+	return nil
+}
+
+// Records frames observed by a FrameSender or FrameReceiver to a pcapng
+// capture. Set as the Observer of a satcom.FrameConfig to use. Received
+// frames are recorded as inbound, and sent frames as outbound. Frames
+// with no data at the recorded layer (such as idle frames) are skipped.
+type Recorder struct {
+	w        *Writer
+	layer    Layer
+	linkType LinkType
+
+	mu  sync.Mutex
+	err error
+}
+
+func NewRecorder(w *Writer, cfg RecorderConfig) (*Recorder, error) {
+	if err := cfg.Err(); err != nil {
+		return nil, err
+	}
+
+	rec := Recorder{
+		w:        w,
+		layer:    cfg.Layer,
+		linkType: cfg.LinkType,
+	}
+	if rec.linkType == 0 {
+		rec.linkType = cfg.Layer.LinkType()
+	}
+	return &rec, nil
+}
+
+// Implements the satcom.FrameObserver interface.
+func (r *Recorder) ObserveFrame(dir satcom.FrameDirection, frm *satcom.Frame) {
+	data := frm.Payload
+	if r.layer == LAYER_RAW {
+		data = frm.Raw
+	}
+	if len(data) == 0 {
+		return
+	}
+
+	p := Packet{
+		Timestamp: frm.Timestamp,
+		LinkType:  r.linkType,
+		Direction: DIRECTION_INBOUND,
+		Data:      data,
+	}
+	if dir == satcom.FRAME_DIRECTION_SENT {
+		p.Direction = DIRECTION_OUTBOUND
+	}
+
+	if err := r.w.WritePacket(p); err != nil {
+		r.mu.Lock()
+		if r.err == nil {
+			r.err = err
+		}
+		r.mu.Unlock()
+	}
+}
+
+// Returns the first error encountered while recording frames, as
+// these cannot be reported to the FrameSender or FrameReceiver.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
@@ -0,0 +1,222 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package pcapng
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"testing"
+
+	satcom "github.com/antaris-inc/go-satcom"
+	"github.com/antaris-inc/go-satcom/example"
+)
+
+func TestRecorder(t *testing.T) {
+	cfg, err := example.MakeSatlabSRS4FrameConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var capt bytes.Buffer
+	w := NewWriter(&capt)
+
+	raw, err := NewRecorder(w, RecorderConfig{Layer: LAYER_RAW})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	csp, err := NewRecorder(w, RecorderConfig{Layer: LAYER_PAYLOAD, LinkType: LINKTYPE_USER2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// frames are recorded at the raw layer as sent, and at the payload
+	// layer (holding CSP packets) as received
+	sendCfg := cfg
+	sendCfg.Observer = raw
+	recvCfg := cfg
+	recvCfg.Observer = csp
+
+	var stream bytes.Buffer
+	fs, err := satcom.NewFrameSender(sendCfg, &stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgs := [][]byte{
+		[]byte("first"),
+		{},
+		[]byte("second"),
+	}
+	for _, msg := range msgs {
+		if err := fs.Send(msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	sent := append([]byte{}, stream.Bytes()...)
+
+	fr, err := satcom.NewFrameReceiver(recvCfg, &stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for {
+		if _, err := fr.Next(context.Background()); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := raw.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := csp.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []Packet
+	r := NewReader(bytes.NewReader(capt.Bytes()))
+	for {
+		p, err := r.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		p.Data = append([]byte{}, p.Data...)
+		got = append(got, p)
+	}
+
+	// the empty message is only recorded at the raw layer
+	frameN := len(sent) / len(msgs)
+	want := []struct {
+		linkType  LinkType
+		direction Direction
+		data      []byte
+	}{
+		{LINKTYPE_USER0, DIRECTION_OUTBOUND, sent[:frameN]},
+		{LINKTYPE_USER0, DIRECTION_OUTBOUND, sent[frameN : 2*frameN]},
+		{LINKTYPE_USER0, DIRECTION_OUTBOUND, sent[2*frameN:]},
+		{LINKTYPE_USER2, DIRECTION_INBOUND, msgs[0]},
+		{LINKTYPE_USER2, DIRECTION_INBOUND, msgs[2]},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("unexpected packet count: want=%d got=%d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].LinkType != want[i].linkType || got[i].Direction != want[i].direction || !bytes.HasPrefix(got[i].Data, want[i].data) {
+			t.Errorf("case %d: unexpected result: want=%v/%v/% x got=%v/%v/% x", i, want[i].linkType, want[i].direction, want[i].data, got[i].LinkType, got[i].Direction, got[i].Data)
+		}
+	}
+
+	// raw frames can be decoded again from the capture
+	s := NewStream(NewReader(bytes.NewReader(capt.Bytes())), func(p Packet) bool {
+		return p.LinkType == LINKTYPE_USER0
+	})
+	replayCfg := cfg
+	replayCfg.DropIdleFrames = true
+	fr, err = satcom.NewFrameReceiver(replayCfg, s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, i := range []int{0, 2} {
+		frm, err := fr.Next(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := frm.Payload[:len(msgs[i])]; !reflect.DeepEqual(msgs[i], got) {
+			t.Errorf("unexpected replayed payload: want=% x got=% x", msgs[i], got)
+		}
+	}
+}
+
+// Confirms each layer records different data for the same frame.
+func TestRecorder_Layers(t *testing.T) {
+	cfg, err := example.MakeSatlabSRS4FrameConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var stream bytes.Buffer
+	fs, err := satcom.NewFrameSender(cfg, &stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.Send([]byte("hello")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fr, err := satcom.NewFrameReceiver(cfg, &stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	frm, err := fr.Next(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		layer    Layer
+		linkType LinkType
+		data     []byte
+	}{
+		{LAYER_RAW, LINKTYPE_USER0, frm.Raw},
+		{LAYER_PAYLOAD, LINKTYPE_USER1, frm.Payload},
+	}
+
+	if bytes.Equal(frm.Raw, frm.Payload) {
+		t.Fatalf("expected raw frame and payload to differ")
+	}
+
+	for i, tt := range tests {
+		var capt bytes.Buffer
+		rec, err := NewRecorder(NewWriter(&capt), RecorderConfig{Layer: tt.layer})
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+		rec.ObserveFrame(satcom.FRAME_DIRECTION_RECEIVED, frm)
+		if err := rec.Err(); err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+
+		p, err := NewReader(bytes.NewReader(capt.Bytes())).Next()
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+		if p.LinkType != tt.linkType || !reflect.DeepEqual(tt.data, p.Data) {
+			t.Errorf("case %d: unexpected result: want=%v/% x got=%v/% x", i, tt.linkType, tt.data, p.LinkType, p.Data)
+		}
+	}
+}
+
+func TestRecorderConfig_Err(t *testing.T) {
+	tests := []struct {
+		cfg     RecorderConfig
+		wantErr bool
+	}{
+		{RecorderConfig{Layer: LAYER_RAW}, false},
+		{RecorderConfig{Layer: LAYER_PAYLOAD, LinkType: LINKTYPE_AX25}, false},
+		{RecorderConfig{Layer: LAYER_PAYLOAD}, false},
+		{RecorderConfig{Layer: Layer(2)}, true},
+	}
+
+	for i, tt := range tests {
+		if err := tt.cfg.Err(); (err != nil) != tt.wantErr {
+			t.Errorf("case %d: unexpected result: wantErr=%v got=%v", i, tt.wantErr, err)
+		}
+	}
+}
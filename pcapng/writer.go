@@ -0,0 +1,163 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package pcapng
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Identifies the application that created a capture.
+const USER_APPLICATION = "go-satcom"
+
+// Writes packets to a pcapng capture, using little-endian byte order and
+// nanosecond timestamps. A single section is written, with an interface
+// description for each LinkType used. Safe for concurrent use.
+type Writer struct {
+	mu  sync.Mutex
+	dst io.Writer
+
+	// set once the section header is written
+	started bool
+
+	// interface index for each link type used
+	interfaces map[LinkType]uint32
+
+	buf []byte
+}
+
+func NewWriter(dst io.Writer) *Writer {
+	return &Writer{
+		dst:        dst,
+		interfaces: map[LinkType]uint32{},
+	}
+}
+
+// Write a single packet, along with any section header or interface
+// description that must precede it.
+func (w *Writer) WritePacket(p Packet) error {
+	if len(p.Data) > MAX_BLOCK_SIZE {
+		return errors.New("packet exceeds max size")
+	}
+	if p.Direction > DIRECTION_OUTBOUND {
+		return errors.New("invalid packet direction")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf := w.buf[:0]
+
+	if !w.started {
+		buf = appendSectionHeader(buf)
+	}
+
+	iface, ok := w.interfaces[p.LinkType]
+	if !ok {
+		iface = uint32(len(w.interfaces))
+		buf = appendInterface(buf, p.LinkType)
+	}
+
+	buf = appendEnhancedPacket(buf, iface, p)
+	w.buf = buf
+
+	if _, err := w.dst.Write(buf); err != nil {
+		return err
+	}
+
+	// only record state once written, so a failed write is retried in full
+	w.started = true
+	w.interfaces[p.LinkType] = iface
+
+	return nil
+}
+
+// Append a block of the given type, with a body populated by the provided
+// function. Block length fields are filled in once the body is known.
+func appendBlock(dst []byte, typ uint32, body func([]byte) []byte) []byte {
+	start := len(dst)
+	dst = binary.LittleEndian.AppendUint32(dst, typ)
+	dst = binary.LittleEndian.AppendUint32(dst, 0)
+	dst = body(dst)
+
+	n := uint32(len(dst) - start + 4)
+	binary.LittleEndian.PutUint32(dst[start+4:], n)
+	return binary.LittleEndian.AppendUint32(dst, n)
+}
+
+// Append an option, padded to a multiple of 4 bytes.
+func appendOption(dst []byte, code uint16, value []byte) []byte {
+	dst = binary.LittleEndian.AppendUint16(dst, code)
+	dst = binary.LittleEndian.AppendUint16(dst, uint16(len(value)))
+	dst = append(dst, value...)
+	for i := len(value); i < pad4(len(value)); i++ {
+		dst = append(dst, 0)
+	}
+	return dst
+}
+
+func appendEndOfOptions(dst []byte) []byte {
+	return binary.LittleEndian.AppendUint32(dst, OPTION_END_OF_OPTIONS)
+}
+
+func appendSectionHeader(dst []byte) []byte {
+	return appendBlock(dst, BLOCK_TYPE_SECTION_HEADER, func(b []byte) []byte {
+		b = binary.LittleEndian.AppendUint32(b, BYTE_ORDER_MAGIC)
+		b = binary.LittleEndian.AppendUint16(b, 1) // major version
+		b = binary.LittleEndian.AppendUint16(b, 0) // minor version
+
+		// section length is not known
+		b = binary.LittleEndian.AppendUint64(b, 0xFFFFFFFFFFFFFFFF)
+
+		b = appendOption(b, OPTION_SHB_USER_APPLICATION, []byte(USER_APPLICATION))
+		return appendEndOfOptions(b)
+	})
+}
+
+func appendInterface(dst []byte, lt LinkType) []byte {
+	return appendBlock(dst, BLOCK_TYPE_INTERFACE, func(b []byte) []byte {
+		b = binary.LittleEndian.AppendUint16(b, uint16(lt))
+		b = binary.LittleEndian.AppendUint16(b, 0) // reserved
+		b = binary.LittleEndian.AppendUint32(b, 0) // no snap length
+
+		// timestamps in nanoseconds
+		b = appendOption(b, OPTION_IF_TIMESTAMP_RESOLUTION, []byte{9})
+		return appendEndOfOptions(b)
+	})
+}
+
+func appendEnhancedPacket(dst []byte, iface uint32, p Packet) []byte {
+	return appendBlock(dst, BLOCK_TYPE_ENHANCED_PACKET, func(b []byte) []byte {
+		ts := uint64(p.Timestamp.UnixNano())
+		b = binary.LittleEndian.AppendUint32(b, iface)
+		b = binary.LittleEndian.AppendUint32(b, uint32(ts>>32))
+		b = binary.LittleEndian.AppendUint32(b, uint32(ts))
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(p.Data))) // captured
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(p.Data))) // original
+
+		b = append(b, p.Data...)
+		for i := len(p.Data); i < pad4(len(p.Data)); i++ {
+			b = append(b, 0)
+		}
+
+		if p.Direction != DIRECTION_UNKNOWN {
+			b = appendOption(b, OPTION_EPB_FLAGS, binary.LittleEndian.AppendUint32(nil, uint32(p.Direction)))
+			b = appendEndOfOptions(b)
+		}
+		return b
+	})
+}
@@ -0,0 +1,112 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package pcapng
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWriter_RoundTrip(t *testing.T) {
+	t0 := time.Unix(1700000000, 123456789)
+
+	input := []Packet{
+		{Timestamp: t0, LinkType: LINKTYPE_USER0, Direction: DIRECTION_INBOUND, Data: []byte{0x01, 0x02, 0x03}},
+		{Timestamp: t0.Add(time.Millisecond), LinkType: LINKTYPE_USER2, Direction: DIRECTION_OUTBOUND, Data: []byte{0x04}},
+		{Timestamp: t0.Add(time.Second), LinkType: LINKTYPE_USER0, Data: []byte{0x05, 0x06, 0x07, 0x08, 0x09}},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, p := range input {
+		if err := w.WritePacket(p); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// all blocks are padded
+	if buf.Len()%4 != 0 {
+		t.Errorf("unexpected capture length %d", buf.Len())
+	}
+
+	// interfaces are numbered in order of first use
+	want := []Packet{
+		{Timestamp: t0, LinkType: LINKTYPE_USER0, Direction: DIRECTION_INBOUND, Data: []byte{0x01, 0x02, 0x03}, Interface: 0},
+		{Timestamp: t0.Add(time.Millisecond), LinkType: LINKTYPE_USER2, Direction: DIRECTION_OUTBOUND, Data: []byte{0x04}, Interface: 1},
+		{Timestamp: t0.Add(time.Second), LinkType: LINKTYPE_USER0, Data: []byte{0x05, 0x06, 0x07, 0x08, 0x09}, Interface: 0},
+	}
+
+	r := NewReader(&buf)
+	for i := range want {
+		got, err := r.Next()
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+		if !got.Timestamp.Equal(want[i].Timestamp) {
+			t.Errorf("case %d: unexpected timestamp: want=%v got=%v", i, want[i].Timestamp, got.Timestamp)
+		}
+		got.Timestamp = want[i].Timestamp
+		if !reflect.DeepEqual(want[i], got) {
+			t.Errorf("case %d: unexpected result: want=%+v got=%+v", i, want[i], got)
+		}
+	}
+
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("unexpected error: want=%v got=%v", io.EOF, err)
+	}
+}
+
+func TestWriter_SectionHeader(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.WritePacket(Packet{Data: []byte{0x01}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b := buf.Bytes()
+	want := []byte{
+		0x0A, 0x0D, 0x0D, 0x0A, // block type
+		0x30, 0x00, 0x00, 0x00, // block length
+		0x4D, 0x3C, 0x2B, 0x1A, // byte order magic
+		0x01, 0x00, 0x00, 0x00, // version 1.0
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, // section length
+		0x04, 0x00, 0x09, 0x00, // shb_userappl
+		'g', 'o', '-', 's', 'a', 't', 'c', 'o', 'm', 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, // opt_endofopt
+		0x30, 0x00, 0x00, 0x00, // block length
+	}
+	if got := b[:len(want)]; !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=% x got=% x", want, got)
+	}
+
+	// followed by an interface description
+	if typ := binary.LittleEndian.Uint32(b[len(want):]); typ != BLOCK_TYPE_INTERFACE {
+		t.Errorf("unexpected block type: want=%d got=%d", BLOCK_TYPE_INTERFACE, typ)
+	}
+}
+
+func TestWriter_Invalid(t *testing.T) {
+	w := NewWriter(io.Discard)
+	if err := w.WritePacket(Packet{Direction: 3}); err == nil {
+		t.Errorf("expected error for invalid direction")
+	}
+	if err := w.WritePacket(Packet{Data: make([]byte, MAX_BLOCK_SIZE+1)}); err == nil {
+		t.Errorf("expected error for oversized packet")
+	}
+}
@@ -0,0 +1,257 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Transmit priority of a message submitted to a QueuedSender. Levels
+// mirror those of CSP, with lower values sent first.
+type Priority int
+
+const (
+	PRIORITY_CRITICAL = Priority(0)
+	PRIORITY_HIGH     = Priority(1)
+	PRIORITY_NORM     = Priority(2)
+	PRIORITY_LOW      = Priority(3)
+
+	NUM_PRIORITIES = 4
+)
+
+func (p Priority) Err() error {
+	if p < PRIORITY_CRITICAL || p > PRIORITY_LOW {
+		return fmt.Errorf("Priority must be 0-3, got %d", p)
+	}
+	return nil
+}
+
+// Returned for messages submitted to (or still queued by) a closed sender.
+var ErrSenderClosed = errors.New("sender closed")
+
+type QueuedSenderConfig struct {
+	// Framing applied to all messages
+	FrameConfig FrameConfig
+
+	// Maximum number of messages waiting to be sent, across all
+	// priorities, beyond which Submit will block. Defaults to 1
+	// if unset.
+	QueueSize int
+}
+
+func (cfg *QueuedSenderConfig) Err() error {
+	if err := cfg.FrameConfig.Err(); err != nil {
+		return err
+	}
+
+	if cfg.QueueSize < 0 {
+		return errors.New("QueueSize must not be negative")
+	}
+
+	return nil
+}
+
+func NewQueuedSender(cfg QueuedSenderConfig, dst io.Writer) (*QueuedSender, error) {
+	if err := cfg.Err(); err != nil {
+		return nil, err
+	}
+
+	fs, err := NewFrameSender(cfg.FrameConfig, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	queueN := cfg.QueueSize
+	if queueN == 0 {
+		queueN = 1
+	}
+
+	qs := QueuedSender{
+		fs:     fs,
+		slots:  make(chan struct{}, queueN),
+		ready:  make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}
+	return &qs, nil
+}
+
+// Accepts messages from any number of goroutines, writing them to a single
+// destination in order of priority. Messages of equal priority are sent in
+// the order they were submitted. Lower priority messages are only sent
+// once no higher priority messages are waiting.
+type QueuedSender struct {
+	fs *FrameSender
+
+	// Holds one entry for each queued message, providing backpressure
+	slots chan struct{}
+
+	// Signals Run that a message has been queued
+	ready chan struct{}
+
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	// Only one call to Run may be active at a time
+	runMu sync.Mutex
+
+	mu     sync.Mutex
+	queues [NUM_PRIORITIES][]*queuedMessage
+}
+
+type queuedMessage struct {
+	msg  []byte
+	done chan error
+}
+
+// Queue a message to be sent by Run, blocking while the queue is full until
+// space is available or the context is cancelled. The returned channel
+// receives the outcome of the send operation once the message has been
+// written. The message is copied, so may be reused once Submit returns.
+// Messages that cannot be encoded are rejected immediately.
+func (s *QueuedSender) Submit(ctx context.Context, prio Priority, msg []byte) (<-chan error, error) {
+	if err := prio.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.fs.checkMessageSize(len(msg)); err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+	case <-s.closed:
+		return nil, ErrSenderClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	qm := queuedMessage{
+		msg:  make([]byte, len(msg)),
+		done: make(chan error, 1),
+	}
+	copy(qm.msg, msg)
+
+	s.mu.Lock()
+	select {
+	case <-s.closed:
+		s.mu.Unlock()
+		<-s.slots
+		return nil, ErrSenderClosed
+	default:
+	}
+	s.queues[prio] = append(s.queues[prio], &qm)
+	s.mu.Unlock()
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+
+	return qm.done, nil
+}
+
+// Queue a message and wait for it to be sent. If the context is cancelled
+// after the message has been queued, it will still be sent.
+func (s *QueuedSender) Send(ctx context.Context, prio Priority, msg []byte) error {
+	done, err := s.Submit(ctx, prio, msg)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Write queued messages to the destination until the context is cancelled
+// or the sender is closed. The outcome of each write is reported to the
+// submitter, and failures do not stop Run. Messages still queued when the
+// context is cancelled will be sent by a subsequent call.
+func (s *QueuedSender) Run(ctx context.Context) error {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	for {
+		// leave remaining messages queued for a subsequent call
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		qm := s.pop()
+		if qm == nil {
+			select {
+			case <-s.ready:
+				continue
+			case <-s.closed:
+				return ErrSenderClosed
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		qm.done <- s.fs.SendContext(ctx, qm.msg)
+	}
+}
+
+// Remove the next message from the queue, returning nil if empty.
+func (s *QueuedSender) pop() *queuedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, q := range s.queues {
+		if len(q) == 0 {
+			continue
+		}
+		qm := q[0]
+		q[0] = nil
+		s.queues[i] = q[1:]
+		<-s.slots
+		return qm
+	}
+
+	return nil
+}
+
+// Stop accepting messages, failing all queued messages with ErrSenderClosed
+// and causing Run to return. A message being written when Close is called
+// is unaffected.
+func (s *QueuedSender) Close() error {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		close(s.closed)
+		for i, q := range s.queues {
+			for _, qm := range q {
+				qm.done <- ErrSenderClosed
+				<-s.slots
+			}
+			s.queues[i] = nil
+		}
+		s.mu.Unlock()
+	})
+	return nil
+}
+
+// Returns a snapshot of the sender counters. This is safe to call
+// while Run is active.
+func (s *QueuedSender) Stats() SenderStats {
+	return s.fs.Stats()
+}
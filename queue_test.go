@@ -0,0 +1,265 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func makeQueuedSenderTestConfig(queueN int) QueuedSenderConfig {
+	return QueuedSenderConfig{
+		FrameConfig: FrameConfig{
+			FrameSyncMarker: []byte{0xFF},
+			FrameSize:       1,
+		},
+		QueueSize: queueN,
+	}
+}
+
+func TestQueuedSender_Priority(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	qs, err := NewQueuedSender(makeQueuedSenderTestConfig(8), buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	submissions := []struct {
+		prio Priority
+		msg  byte
+	}{
+		{PRIORITY_LOW, 0x01},
+		{PRIORITY_NORM, 0x02},
+		{PRIORITY_CRITICAL, 0x03},
+		{PRIORITY_HIGH, 0x04},
+		{PRIORITY_CRITICAL, 0x05},
+	}
+
+	// everything is queued before Run starts
+	dones := []<-chan error{}
+	for i, sub := range submissions {
+		done, err := qs.Submit(context.Background(), sub.prio, []byte{sub.msg})
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+		dones = append(dones, done)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runErr := make(chan error, 1)
+	go func() { runErr <- qs.Run(ctx) }()
+
+	for i, done := range dones {
+		if err := <-done; err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+		}
+	}
+
+	cancel()
+	if err := <-runErr; err != context.Canceled {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	want := []byte{0xFF, 0x03, 0xFF, 0x05, 0xFF, 0x04, 0xFF, 0x02, 0xFF, 0x01}
+	if got := buf.Bytes(); !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=% x got=% x", want, got)
+	}
+}
+
+func TestQueuedSender_Concurrent(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	qs, err := NewQueuedSender(makeQueuedSenderTestConfig(2), buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go qs.Run(ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := qs.Send(context.Background(), Priority(i%4), []byte{byte(i)}); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	got := []int{}
+	for i, b := range buf.Bytes() {
+		if i%2 == 1 {
+			got = append(got, int(b))
+		}
+	}
+	sort.Ints(got)
+
+	want := []int{}
+	for i := 0; i < 32; i++ {
+		want = append(want, i)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=%v got=%v", want, got)
+	}
+
+	if st := qs.Stats(); st.FramesSent != 32 {
+		t.Errorf("unexpected frames sent: want=32 got=%d", st.FramesSent)
+	}
+}
+
+func TestQueuedSender_Backpressure(t *testing.T) {
+	qs, err := NewQueuedSender(makeQueuedSenderTestConfig(1), bytes.NewBuffer(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := qs.Submit(context.Background(), PRIORITY_NORM, []byte{0x01}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := qs.Submit(ctx, PRIORITY_CRITICAL, []byte{0x02}); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestQueuedSender_SubmitInvalid(t *testing.T) {
+	qs, err := NewQueuedSender(makeQueuedSenderTestConfig(1), bytes.NewBuffer(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		prio Priority
+		msg  []byte
+	}{
+		{Priority(4), []byte{0x01}},
+		{Priority(-1), []byte{0x01}},
+		{PRIORITY_NORM, []byte{0x01, 0x02}},
+	}
+
+	for i, tt := range tests {
+		if _, err := qs.Submit(context.Background(), tt.prio, tt.msg); err == nil {
+			t.Errorf("case %d: expected non-nil error", i)
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestQueuedSender_Failure(t *testing.T) {
+	qs, err := NewQueuedSender(makeQueuedSenderTestConfig(1), failingWriter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go qs.Run(ctx)
+
+	// failures are reported per message without stopping Run
+	for i := 0; i < 2; i++ {
+		if err := qs.Send(context.Background(), PRIORITY_NORM, []byte{0x01}); err == nil {
+			t.Errorf("case %d: expected non-nil error", i)
+		}
+	}
+}
+
+func TestQueuedSender_Close(t *testing.T) {
+	qs, err := NewQueuedSender(makeQueuedSenderTestConfig(1), bytes.NewBuffer(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done, err := qs.Submit(context.Background(), PRIORITY_NORM, []byte{0x01})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	qs.Close()
+
+	if err := <-done; err != ErrSenderClosed {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := qs.Submit(context.Background(), PRIORITY_NORM, []byte{0x01}); err != ErrSenderClosed {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := qs.Run(context.Background()); err != ErrSenderClosed {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestQueuedSender_RunCancelled(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	qs, err := NewQueuedSender(makeQueuedSenderTestConfig(3), buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dones := []<-chan error{}
+	for i := 0; i < 3; i++ {
+		done, err := qs.Submit(context.Background(), PRIORITY_NORM, []byte{byte(i)})
+		if err != nil {
+			t.Fatalf("case %d: unexpected error: %v", i, err)
+		}
+		dones = append(dones, done)
+	}
+
+	// nothing is dequeued once the context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := qs.Run(ctx); err != context.Canceled {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for i, done := range dones {
+		select {
+		case err := <-done:
+			t.Fatalf("case %d: unexpected completion: %v", i, err)
+		default:
+		}
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go qs.Run(ctx)
+
+	for i, done := range dones {
+		if err := <-done; err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+		}
+	}
+
+	want := []byte{0xFF, 0x00, 0xFF, 0x01, 0xFF, 0x02}
+	if got := buf.Bytes(); !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=% x got=% x", want, got)
+	}
+}
@@ -0,0 +1,129 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Returned by a FrameSender configured with a non-blocking RateLimit
+// when a frame cannot be sent without exceeding the limit.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// Paces the output of a FrameSender to match the bit rate of a link,
+// preventing a transmitter's buffer from being overrun. Frames are
+// accounted for at their fully encoded size, including the sync marker.
+type RateLimit struct {
+	// Link bit rate, in bits per second
+	BitRate int
+
+	// Maximum number of bytes that may be written in a single burst
+	// after a period of inactivity. Defaults to the size of a single
+	// frame (including sync marker) if unset, and must be at least
+	// that large otherwise. When a Codec is used, frames may exceed
+	// this size, in which case they are sent once a full burst is
+	// available.
+	BurstSize int
+
+	// Return ErrRateLimited from Send rather than waiting when a
+	// frame cannot be sent immediately.
+	NonBlocking bool
+}
+
+func (rl *RateLimit) Err() error {
+	if rl.BitRate <= 0 {
+		return errors.New("BitRate must be greater than 0")
+	}
+
+	if rl.BurstSize < 0 {
+		return errors.New("BurstSize must not be negative")
+	}
+
+	return nil
+}
+
+func newTokenBucket(rl *RateLimit, frameN int) *tokenBucket {
+	size := rl.BurstSize
+	if size == 0 {
+		size = frameN
+	}
+
+	return &tokenBucket{
+		rate:   float64(rl.BitRate) / 8,
+		size:   float64(size),
+		tokens: float64(size),
+	}
+}
+
+// Tracks the number of bytes that may be written, replenished at a
+// fixed rate up to a maximum.
+type tokenBucket struct {
+	// bytes per second
+	rate float64
+
+	size   float64
+	tokens float64
+	last   time.Time
+}
+
+// Consume n tokens if available, otherwise returning the time to wait
+// until they will be.
+func (b *tokenBucket) take(n int, now time.Time) time.Duration {
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.size {
+			b.tokens = b.size
+		}
+	}
+	b.last = now
+
+	// A frame larger than the bucket (as the overhead added by a Codec
+	// is not known in advance) is allowed once the bucket is full, with
+	// the deficit repaid before any subsequent frame.
+	want := float64(n)
+	if b.tokens >= want || b.tokens >= b.size {
+		b.tokens -= want
+		return 0
+	}
+
+	if want > b.size {
+		want = b.size
+	}
+	return time.Duration((want - b.tokens) / b.rate * float64(time.Second))
+}
+
+// Block until n bytes may be written, or the context is cancelled.
+func (b *tokenBucket) wait(ctx context.Context, n int, nonBlocking bool) error {
+	for {
+		d := b.take(n, time.Now())
+		if d == 0 {
+			return nil
+		}
+
+		if nonBlocking {
+			return ErrRateLimited
+		}
+
+		t := time.NewTimer(d)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
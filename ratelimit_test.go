@@ -0,0 +1,181 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestRateLimit_Err(t *testing.T) {
+	tests := []struct {
+		rl      RateLimit
+		wantErr bool
+	}{
+		{
+			rl:      RateLimit{BitRate: 9600},
+			wantErr: false,
+		},
+		{
+			rl:      RateLimit{BitRate: 9600, BurstSize: 6},
+			wantErr: false,
+		},
+		{
+			rl:      RateLimit{},
+			wantErr: true,
+		},
+		{
+			rl:      RateLimit{BitRate: 9600, BurstSize: -1},
+			wantErr: true,
+		},
+		// burst must fit a complete frame
+		{
+			rl:      RateLimit{BitRate: 9600, BurstSize: 5},
+			wantErr: true,
+		},
+	}
+
+	for i, tt := range tests {
+		cfg := FrameConfig{
+			FrameSyncMarker: []byte{0x01, 0x02},
+			FrameSize:       4,
+			RateLimit:       &tt.rl,
+		}
+		err := cfg.Err()
+		if tt.wantErr != (err != nil) {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	// 100 bytes per second, with a burst of 200 bytes
+	b := newTokenBucket(&RateLimit{BitRate: 800, BurstSize: 200}, 100)
+	start := time.Unix(0, 0)
+
+	tests := []struct {
+		n       int
+		elapsed time.Duration
+		want    time.Duration
+	}{
+		// initial burst is available immediately
+		{n: 100, elapsed: 0, want: 0},
+		{n: 100, elapsed: 0, want: 0},
+		{n: 100, elapsed: 0, want: time.Second},
+
+		// partially replenished
+		{n: 100, elapsed: 500 * time.Millisecond, want: 500 * time.Millisecond},
+		{n: 100, elapsed: time.Second, want: 0},
+
+		// replenishment is capped at the burst size
+		{n: 200, elapsed: 10 * time.Second, want: 0},
+		{n: 50, elapsed: 10 * time.Second, want: 500 * time.Millisecond},
+
+		// oversized frames wait for a full bucket, leaving a deficit
+		{n: 300, elapsed: 11 * time.Second, want: time.Second},
+		{n: 300, elapsed: 12 * time.Second, want: 0},
+		{n: 100, elapsed: 12 * time.Second, want: 2 * time.Second},
+	}
+
+	for i, tt := range tests {
+		got := b.take(tt.n, start.Add(tt.elapsed))
+		if got != tt.want {
+			t.Errorf("case %d: unexpected result: want=%v got=%v", i, tt.want, got)
+		}
+	}
+}
+
+func TestFrameSender_RateLimit(t *testing.T) {
+	cfg := FrameConfig{
+		FrameSyncMarker: []byte{0x01, 0x02, 0x03, 0x04},
+		FrameSize:       96,
+
+		// 10ms per frame, including sync marker
+		RateLimit: &RateLimit{BitRate: 80000},
+	}
+
+	fs, err := NewFrameSender(cfg, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := fs.Send(make([]byte, 96)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// the first frame is sent immediately
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("frames sent too quickly: %v", elapsed)
+	}
+}
+
+func TestFrameSender_RateLimitNonBlocking(t *testing.T) {
+	cfg := FrameConfig{
+		FrameSyncMarker: []byte{0x01, 0x02, 0x03, 0x04},
+		FrameSize:       96,
+		RateLimit:       &RateLimit{BitRate: 8, NonBlocking: true},
+	}
+
+	fs, err := NewFrameSender(cfg, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := fs.Send(make([]byte, 96)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.Send(make([]byte, 96)); err != ErrRateLimited {
+		t.Fatalf("unexpected error: want=%v got=%v", ErrRateLimited, err)
+	}
+
+	wantStats := SenderStats{
+		FramesSent:   1,
+		BytesWritten: 100,
+		SendErrors:   1,
+		RateLimited:  1,
+	}
+	if got := fs.Stats(); got != wantStats {
+		t.Errorf("unexpected stats: want=%+v got=%+v", wantStats, got)
+	}
+}
+
+func TestFrameSender_RateLimitCancelled(t *testing.T) {
+	cfg := FrameConfig{
+		FrameSyncMarker: []byte{0x01, 0x02, 0x03, 0x04},
+		FrameSize:       96,
+		RateLimit:       &RateLimit{BitRate: 8},
+	}
+
+	fs, err := NewFrameSender(cfg, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := fs.Send(make([]byte, 96)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := fs.SendContext(ctx, make([]byte, 96)); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
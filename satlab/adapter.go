@@ -35,3 +35,13 @@ func (a *SpaceframeAdapter) Wrap(msg []byte) ([]byte, error) {
 func (a *SpaceframeAdapter) Unwrap(frm []byte) ([]byte, error) {
 	return Deframe(frm, &a.SpaceframeConfig)
 }
+
+// Append the Spaceframe encoding of the provided message to dst.
+func (a *SpaceframeAdapter) AppendWrap(dst, msg []byte) ([]byte, error) {
+	return AppendEnframe(dst, msg, &a.SpaceframeConfig)
+}
+
+// Deframe the provided Spaceframe, returning a subslice containing its payload.
+func (a *SpaceframeAdapter) UnwrapInPlace(frm []byte) ([]byte, error) {
+	return Deframe(frm, &a.SpaceframeConfig)
+}
@@ -14,7 +14,10 @@
 
 package satlab
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestSpaceframeAdapter_MessageSize(t *testing.T) {
 	ad := SpaceframeAdapter{
@@ -49,3 +52,31 @@ func TestSpaceframeAdapter_MessageSize_TooLarge(t *testing.T) {
 		t.Fatalf("expected non-nil error")
 	}
 }
+
+func TestSpaceframeAdapter_AppendWrap(t *testing.T) {
+	ad := SpaceframeAdapter{
+		SpaceframeConfig{
+			Type:            SPACEFRAME_TYPE_CSP,
+			PayloadDataSize: 4,
+			WithASM:         true,
+		},
+	}
+
+	got, err := ad.AppendWrap([]byte{0xFF}, []byte{0x01, 0x02})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []byte{0xFF, 0x1A, 0xCF, 0xFC, 0x1D, 0x00, 0x02, 0x01, 0x02, 0x00, 0x00}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=% x got=% x", want, got)
+	}
+
+	msg, err := ad.UnwrapInPlace(got[1:])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []byte{0x01, 0x02}; !reflect.DeepEqual(want, msg) {
+		t.Errorf("unexpected result: want=% x got=% x", want, msg)
+	}
+}
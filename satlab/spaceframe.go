@@ -15,10 +15,10 @@
 package satlab
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"reflect"
 )
 
 const (
@@ -77,6 +77,11 @@ func (h *SpaceframeHeader) Err() error {
 }
 
 func (h *SpaceframeHeader) ToBytes() []byte {
+	return h.AppendBytes(make([]byte, 0, SPACEFRAME_HEADER_LENGTH_BYTES))
+}
+
+// Append the encoded header to the provided slice.
+func (h *SpaceframeHeader) AppendBytes(bs []byte) []byte {
 	var header uint16
 
 	cursor := 0
@@ -90,10 +95,7 @@ func (h *SpaceframeHeader) ToBytes() []byte {
 	cursor += FLEN_LENGTH
 	header |= (uint16(h.Length) << (16 - cursor))
 
-	bs := make([]byte, SPACEFRAME_HEADER_LENGTH_BYTES)
-	binary.BigEndian.PutUint16(bs, header)
-
-	return bs
+	return binary.BigEndian.AppendUint16(bs, header)
 }
 
 func (h *SpaceframeHeader) FromBytes(bs []byte) error {
@@ -121,6 +123,11 @@ func (h *SpaceframeHeader) FromBytes(bs []byte) error {
 }
 
 func Enframe(msg []byte, cfg *SpaceframeConfig) ([]byte, error) {
+	return AppendEnframe(make([]byte, 0, cfg.FrameSize()), msg, cfg)
+}
+
+// Behaves like Enframe, but appends the frame to the provided slice.
+func AppendEnframe(dst []byte, msg []byte, cfg *SpaceframeConfig) ([]byte, error) {
 	msgLen := len(msg)
 	if msgLen > cfg.PayloadDataSize {
 		return nil, errors.New("message too large")
@@ -134,19 +141,18 @@ func Enframe(msg []byte, cfg *SpaceframeConfig) ([]byte, error) {
 		return nil, fmt.Errorf("Spaceframe header: %v", err)
 	}
 
-	// start frame with encoded header
-	frm := hdr.ToBytes()
-
-	// create padded message and append to frame
-	pmsg := make([]byte, cfg.PayloadDataSize)
-	copy(pmsg, msg)
-	frm = append(frm, pmsg...)
-
 	if cfg.WithASM {
-		frm = append(SATLAB_ASM, frm...)
+		dst = append(dst, SATLAB_ASM...)
 	}
 
-	return frm, nil
+	// start frame with encoded header
+	dst = hdr.AppendBytes(dst)
+
+	// append message and zero padding
+	dst = append(dst, msg...)
+	dst = append(dst, make([]byte, cfg.PayloadDataSize-msgLen)...)
+
+	return dst, nil
 }
 
 func Deframe(frm []byte, cfg *SpaceframeConfig) ([]byte, error) {
@@ -156,7 +162,7 @@ func Deframe(frm []byte, cfg *SpaceframeConfig) ([]byte, error) {
 
 	if cfg.WithASM {
 		gotASM := frm[0:SATLAB_ASM_LENGTH_BYTES]
-		if !reflect.DeepEqual(gotASM, SATLAB_ASM) {
+		if !bytes.Equal(gotASM, SATLAB_ASM) {
 			return nil, errors.New("Spaceframe ASM mismatch")
 		}
 		frm = frm[SATLAB_ASM_LENGTH_BYTES:]
@@ -0,0 +1,341 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package sim provides an in-memory communication channel with configurable
+// impairments, allowing link behavior such as sync recovery and error
+// correction to be tested deterministically.
+package sim
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Describes the impairments applied to data passing through a Channel.
+// All probabilities are in the range [0, 1].
+type Impairments struct {
+	// Probability of each bit being inverted
+	BitErrorRate float64
+
+	// Probability of a burst of errors starting at each byte, during
+	// which bits are inverted with probability BurstBitErrorRate
+	// (defaulting to 0.5) for BurstLength bytes.
+	BurstProbability  float64
+	BurstLength       int
+	BurstBitErrorRate float64
+
+	// Probability of a random byte being inserted before each byte
+	InsertionRate float64
+
+	// Probability of each byte being deleted
+	DeletionRate float64
+
+	// Probability of the data passed to each call to Write being
+	// dropped entirely, as when a packet is lost
+	DropRate float64
+
+	// Fixed delay before written data may be read
+	Latency time.Duration
+
+	// Maximum random delay added to Latency for each write. Data
+	// is never reordered.
+	Jitter time.Duration
+
+	// Channel capacity in bits per second, which Write is paced to.
+	// Unlimited if zero.
+	BitRate int
+}
+
+func (imp *Impairments) Err() error {
+	probs := []float64{
+		imp.BitErrorRate,
+		imp.BurstProbability,
+		imp.BurstBitErrorRate,
+		imp.InsertionRate,
+		imp.DeletionRate,
+		imp.DropRate,
+	}
+	for _, p := range probs {
+		if p < 0 || p > 1 {
+			return errors.New("probabilities must be between 0 and 1")
+		}
+	}
+
+	if imp.BurstLength < 0 {
+		return errors.New("BurstLength must not be negative")
+	}
+	if imp.BurstProbability > 0 && imp.BurstLength == 0 {
+		return errors.New("BurstLength must be set along with BurstProbability")
+	}
+
+	if imp.Latency < 0 || imp.Jitter < 0 {
+		return errors.New("Latency and Jitter must not be negative")
+	}
+
+	if imp.BitRate < 0 {
+		return errors.New("BitRate must not be negative")
+	}
+
+	return nil
+}
+
+type ChannelConfig struct {
+	Impairments
+
+	// Seed for the random number generator driving all impairments.
+	// Channels sharing a seed and configuration corrupt the same
+	// sequence of writes identically.
+	Seed int64
+}
+
+func NewChannel(cfg ChannelConfig) (*Channel, error) {
+	if err := cfg.Impairments.Err(); err != nil {
+		return nil, err
+	}
+
+	burstBER := cfg.BurstBitErrorRate
+	if burstBER == 0 {
+		burstBER = 0.5
+	}
+
+	ch := Channel{
+		imp:      cfg.Impairments,
+		burstBER: burstBER,
+		rnd:      rand.New(rand.NewSource(cfg.Seed)),
+		notify:   make(chan struct{}),
+	}
+	ch.untilError = ch.nextBitError()
+	return &ch, nil
+}
+
+// Unidirectional in-memory stream, applying impairments to all data
+// written before it may be read. It is safe for concurrent use.
+type Channel struct {
+	imp      Impairments
+	burstBER float64
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+
+	// bits remaining before the next random bit error
+	untilError int64
+
+	// bytes remaining in the current error burst
+	burstLeft int
+
+	// data waiting to be read, in order of delivery
+	queue []chunk
+
+	// closed and replaced whenever the queue changes
+	notify chan struct{}
+
+	// time at which the channel is free to transmit, and at
+	// which the most recent data will be delivered
+	txFree    time.Time
+	deliverAt time.Time
+
+	closed bool
+}
+
+type chunk struct {
+	data []byte
+	at   time.Time
+}
+
+// Apply impairments to the provided data and queue it for delivery,
+// blocking as necessary to honour the channel bit rate.
+func (c *Channel) Write(p []byte) (int, error) {
+	c.mu.Lock()
+
+	if c.closed {
+		c.mu.Unlock()
+		return 0, io.ErrClosedPipe
+	}
+
+	now := time.Now()
+	if c.txFree.Before(now) {
+		c.txFree = now
+	}
+	if c.imp.BitRate > 0 {
+		c.txFree = c.txFree.Add(time.Duration(float64(len(p)*8) / float64(c.imp.BitRate) * float64(time.Second)))
+	}
+	txDone := c.txFree
+
+	dropped := c.imp.DropRate > 0 && c.rnd.Float64() < c.imp.DropRate
+	if !dropped {
+		data := c.impair(p)
+
+		at := txDone.Add(c.imp.Latency)
+		if c.imp.Jitter > 0 {
+			at = at.Add(time.Duration(c.rnd.Int63n(int64(c.imp.Jitter) + 1)))
+		}
+		// never reorder data
+		if at.Before(c.deliverAt) {
+			at = c.deliverAt
+		}
+		c.deliverAt = at
+
+		if len(data) > 0 {
+			c.queue = append(c.queue, chunk{data: data, at: at})
+			c.signal()
+		}
+	}
+
+	c.mu.Unlock()
+
+	// pace the writer to the channel bit rate
+	if wait := time.Until(txDone); wait > 0 {
+		time.Sleep(wait)
+	}
+
+	return len(p), nil
+}
+
+// Read data once it has been delivered, blocking until some is available.
+// Returns io.EOF once the channel is closed and all data has been read.
+func (c *Channel) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for {
+		c.mu.Lock()
+
+		var wait time.Duration
+		if len(c.queue) > 0 {
+			head := &c.queue[0]
+			if wait = time.Until(head.at); wait <= 0 {
+				n := copy(p, head.data)
+				if head.data = head.data[n:]; len(head.data) == 0 {
+					c.queue[0] = chunk{}
+					c.queue = c.queue[1:]
+				}
+				c.mu.Unlock()
+				return n, nil
+			}
+		} else if c.closed {
+			c.mu.Unlock()
+			return 0, io.EOF
+		}
+
+		notify := c.notify
+		c.mu.Unlock()
+
+		if wait > 0 {
+			t := time.NewTimer(wait)
+			select {
+			case <-t.C:
+			case <-notify:
+				t.Stop()
+			}
+		} else {
+			<-notify
+		}
+	}
+}
+
+// Prevent further writes. Data already written may still be read, after
+// which Read returns io.EOF.
+func (c *Channel) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		c.signal()
+	}
+	return nil
+}
+
+// Wake any blocked readers, must be called with mu held.
+func (c *Channel) signal() {
+	close(c.notify)
+	c.notify = make(chan struct{})
+}
+
+// Return a copy of the provided data with byte- and bit-level impairments
+// applied, must be called with mu held.
+func (c *Channel) impair(p []byte) []byte {
+	out := make([]byte, 0, len(p))
+
+	for _, b := range p {
+		if c.imp.InsertionRate > 0 && c.rnd.Float64() < c.imp.InsertionRate {
+			out = append(out, byte(c.rnd.Intn(256)))
+		}
+
+		if c.imp.DeletionRate > 0 && c.rnd.Float64() < c.imp.DeletionRate {
+			continue
+		}
+
+		if c.burstLeft == 0 && c.imp.BurstProbability > 0 && c.rnd.Float64() < c.imp.BurstProbability {
+			c.burstLeft = c.imp.BurstLength
+		}
+		if c.burstLeft > 0 {
+			c.burstLeft--
+			for i := 0; i < 8; i++ {
+				if c.rnd.Float64() < c.burstBER {
+					b ^= 0x80 >> i
+				}
+			}
+		}
+
+		b = c.applyBitErrors(b)
+
+		out = append(out, b)
+	}
+
+	return out
+}
+
+// Invert bits of the provided byte at random positions, spaced according
+// to the bit error rate.
+func (c *Channel) applyBitErrors(b byte) byte {
+	if c.imp.BitErrorRate == 0 {
+		return b
+	}
+
+	pos := int64(0)
+	for pos+c.untilError < 8 {
+		pos += c.untilError
+		b ^= 0x80 >> pos
+		pos++
+		c.untilError = c.nextBitError()
+	}
+	c.untilError -= 8 - pos
+
+	return b
+}
+
+// Draw the number of error-free bits preceding the next bit error from
+// a geometric distribution, avoiding a random draw for every bit.
+func (c *Channel) nextBitError() int64 {
+	p := c.imp.BitErrorRate
+	if p == 0 {
+		return math.MaxInt64
+	}
+	if p == 1 {
+		return 0
+	}
+
+	u := 1 - c.rnd.Float64() // (0, 1]
+	n := math.Floor(math.Log(u) / math.Log(1-p))
+	if n > math.MaxInt64/2 {
+		return math.MaxInt64 / 2
+	}
+	return int64(n)
+}
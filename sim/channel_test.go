@@ -0,0 +1,212 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sim
+
+import (
+	"bytes"
+	"io"
+	"math/bits"
+	"testing"
+	"time"
+)
+
+// Write the input through a channel in chunks, returning everything read.
+func transfer(t *testing.T, cfg ChannelConfig, input []byte, chunkN int) []byte {
+	ch, err := NewChannel(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	go func() {
+		for i := 0; i < len(input); i += chunkN {
+			end := i + chunkN
+			if end > len(input) {
+				end = len(input)
+			}
+			ch.Write(input[i:end])
+		}
+		ch.Close()
+	}()
+
+	out, err := io.ReadAll(ch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return out
+}
+
+func makeInput(n int) []byte {
+	input := make([]byte, n)
+	for i := range input {
+		input[i] = byte(i)
+	}
+	return input
+}
+
+func TestImpairments_Err(t *testing.T) {
+	tests := []struct {
+		imp     Impairments
+		wantErr bool
+	}{
+		{Impairments{}, false},
+		{Impairments{BitErrorRate: 1e-3, BurstProbability: 1e-4, BurstLength: 8}, false},
+		{Impairments{BitErrorRate: 1.5}, true},
+		{Impairments{DropRate: -0.1}, true},
+		{Impairments{BurstProbability: 0.1}, true},
+		{Impairments{Latency: -time.Second}, true},
+		{Impairments{BitRate: -1}, true},
+	}
+
+	for i, tt := range tests {
+		err := tt.imp.Err()
+		if tt.wantErr != (err != nil) {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+		}
+	}
+}
+
+func TestChannel_NoImpairments(t *testing.T) {
+	input := makeInput(4096)
+	got := transfer(t, ChannelConfig{}, input, 100)
+	if !bytes.Equal(input, got) {
+		t.Errorf("unexpected output")
+	}
+}
+
+func TestChannel_Deterministic(t *testing.T) {
+	input := makeInput(4096)
+
+	cfg := ChannelConfig{
+		Impairments: Impairments{
+			BitErrorRate:     1e-3,
+			BurstProbability: 1e-3,
+			BurstLength:      4,
+			InsertionRate:    1e-3,
+			DeletionRate:     1e-3,
+			DropRate:         0.05,
+		},
+		Seed: 42,
+	}
+
+	first := transfer(t, cfg, input, 64)
+	second := transfer(t, cfg, input, 64)
+	if !bytes.Equal(first, second) {
+		t.Errorf("output differs for the same seed")
+	}
+
+	cfg.Seed = 43
+	if third := transfer(t, cfg, input, 64); bytes.Equal(first, third) {
+		t.Errorf("output identical for different seeds")
+	}
+}
+
+func TestChannel_BitErrorRate(t *testing.T) {
+	input := makeInput(100000)
+	cfg := ChannelConfig{
+		Impairments: Impairments{BitErrorRate: 1e-2},
+	}
+
+	got := transfer(t, cfg, input, 1000)
+	if len(got) != len(input) {
+		t.Fatalf("unexpected output length: want=%d got=%d", len(input), len(got))
+	}
+
+	var errs int
+	for i := range input {
+		errs += bits.OnesCount8(input[i] ^ got[i])
+	}
+
+	// expect 8000 errors
+	if errs < 7000 || errs > 9000 {
+		t.Errorf("unexpected bit errors: %d", errs)
+	}
+}
+
+func TestChannel_InsertionDeletion(t *testing.T) {
+	input := makeInput(100000)
+
+	tests := []struct {
+		imp  Impairments
+		minN int
+		maxN int
+	}{
+		{Impairments{InsertionRate: 0.01}, 100800, 101200},
+		{Impairments{DeletionRate: 0.01}, 98800, 99200},
+		{Impairments{DropRate: 0.5}, 40000, 60000},
+	}
+
+	for i, tt := range tests {
+		got := transfer(t, ChannelConfig{Impairments: tt.imp}, input, 1000)
+		if n := len(got); n < tt.minN || n > tt.maxN {
+			t.Errorf("case %d: unexpected output length: %d", i, n)
+		}
+	}
+}
+
+func TestChannel_Latency(t *testing.T) {
+	ch, err := NewChannel(ChannelConfig{
+		Impairments: Impairments{Latency: 20 * time.Millisecond, Jitter: 10 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	ch.Write([]byte{0x01})
+
+	buf := make([]byte, 1)
+	if _, err := ch.Read(buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("data delivered too soon: %v", elapsed)
+	}
+}
+
+func TestChannel_BitRate(t *testing.T) {
+	// 1000 bytes at 10kB/s
+	cfg := ChannelConfig{
+		Impairments: Impairments{BitRate: 80000},
+	}
+
+	start := time.Now()
+	transfer(t, cfg, makeInput(1000), 100)
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("data delivered too quickly: %v", elapsed)
+	}
+}
+
+func TestChannel_Close(t *testing.T) {
+	ch, err := NewChannel(ChannelConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ch.Write([]byte{0x01})
+	ch.Close()
+
+	if _, err := ch.Write([]byte{0x02}); err != io.ErrClosedPipe {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// buffered data is still delivered
+	buf := make([]byte, 2)
+	if n, err := ch.Read(buf); n != 1 || err != nil {
+		t.Errorf("unexpected result: n=%d err=%v", n, err)
+	}
+	if _, err := ch.Read(buf); err != io.EOF {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sim
+
+import (
+	"fmt"
+	"io"
+)
+
+type PipeConfig struct {
+	// Impairments applied to data written to endpoint A and read
+	// from endpoint B
+	AToB Impairments
+
+	// Impairments applied to data written to endpoint B and read
+	// from endpoint A
+	BToA Impairments
+
+	// Seed for the random number generators driving all impairments
+	Seed int64
+}
+
+// Create a bidirectional pair of connected endpoints, each direction
+// subject to its own impairments.
+func NewPipe(cfg PipeConfig) (*Endpoint, *Endpoint, error) {
+	ab, err := NewChannel(ChannelConfig{Impairments: cfg.AToB, Seed: cfg.Seed})
+	if err != nil {
+		return nil, nil, fmt.Errorf("AToB: %v", err)
+	}
+
+	// derive a distinct seed so the directions are independent
+	ba, err := NewChannel(ChannelConfig{Impairments: cfg.BToA, Seed: cfg.Seed + 1})
+	if err != nil {
+		return nil, nil, fmt.Errorf("BToA: %v", err)
+	}
+
+	a := Endpoint{rx: ba, tx: ab}
+	b := Endpoint{rx: ab, tx: ba}
+	return &a, &b, nil
+}
+
+// One end of a simulated bidirectional link.
+type Endpoint struct {
+	rx *Channel
+	tx *Channel
+}
+
+var _ io.ReadWriteCloser = (*Endpoint)(nil)
+
+func (e *Endpoint) Read(p []byte) (int, error) {
+	return e.rx.Read(p)
+}
+
+func (e *Endpoint) Write(p []byte) (int, error) {
+	return e.tx.Write(p)
+}
+
+// Close both directions. Reads at either end return io.EOF once all data
+// already written has been delivered, and writes fail.
+func (e *Endpoint) Close() error {
+	e.tx.Close()
+	return e.rx.Close()
+}
+
+// Close only the outgoing direction, such that the far end reads io.EOF
+// once all data written has been delivered.
+func (e *Endpoint) CloseWrite() error {
+	return e.tx.Close()
+}
@@ -0,0 +1,128 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sim
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/antaris-inc/go-satcom"
+	"github.com/antaris-inc/go-satcom/crc"
+	"github.com/antaris-inc/go-satcom/satlab"
+)
+
+func TestPipe(t *testing.T) {
+	a, b, err := NewPipe(PipeConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	go a.Write([]byte{0x01, 0x02})
+	go b.Write([]byte{0x03})
+
+	buf := make([]byte, 4)
+	if n, _ := b.Read(buf); !reflect.DeepEqual([]byte{0x01, 0x02}, buf[:n]) {
+		t.Errorf("unexpected result: % x", buf[:n])
+	}
+	if n, _ := a.Read(buf); !reflect.DeepEqual([]byte{0x03}, buf[:n]) {
+		t.Errorf("unexpected result: % x", buf[:n])
+	}
+
+	if _, _, err := NewPipe(PipeConfig{BToA: Impairments{BitErrorRate: 2}}); err == nil {
+		t.Errorf("expected non-nil error")
+	}
+}
+
+// Exercise sync recovery of a satcom Link over a noisy channel.
+func TestPipe_SatcomLink(t *testing.T) {
+	crc32Adapter, _ := crc.NewCRC32Adapter(crc.CRC32AdapterConfig{
+		Algorithm: crc.CRC32c,
+	})
+	frmCfg := satcom.FrameConfig{
+		FrameSyncMarker:        satlab.SATLAB_ASM,
+		SyncMarkerMaxBitErrors: 3,
+		FrameSize:              223,
+		ResyncOnFailure:        true,
+		Adapters: []satcom.Adapter{
+			&satlab.SpaceframeAdapter{
+				SpaceframeConfig: satlab.SpaceframeConfig{
+					Type:            satlab.SPACEFRAME_TYPE_CSP,
+					PayloadDataSize: 217,
+				},
+			},
+			crc32Adapter,
+		},
+	}
+	linkCfg := satcom.LinkConfig{Transmit: frmCfg, Receive: frmCfg}
+
+	a, b, err := NewPipe(PipeConfig{
+		AToB: Impairments{
+			BitErrorRate:  1e-4,
+			InsertionRate: 1e-4,
+			DeletionRate:  1e-4,
+		},
+		Seed: 1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tx, err := satcom.NewLink(linkCfg, a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rx, err := satcom.NewLink(linkCfg, b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	const frameN = 200
+	go func() {
+		for i := 0; i < frameN; i++ {
+			tx.Send(context.Background(), []byte{byte(i)})
+		}
+		a.CloseWrite()
+	}()
+
+	var got int
+	for {
+		msg, err := rx.Recv(context.Background())
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			continue
+		}
+		if len(msg) != 1 {
+			t.Errorf("unexpected message: % x", msg)
+		}
+		got++
+	}
+
+	st := rx.Stats().Receive
+	if st.FramesReceived != uint64(got) {
+		t.Errorf("unexpected stats: %+v", st)
+	}
+
+	// most frames survive, and every damaged frame is detected
+	if got < frameN*3/4 || got == frameN {
+		t.Errorf("unexpected frames received: %d of %d", got, frameN)
+	}
+	if st.AdapterErrors[1] == 0 {
+		t.Errorf("expected CRC failures: %+v", st)
+	}
+}
@@ -0,0 +1,94 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+// Counters describing the activity of a FrameReceiver. These are
+// useful in judging link quality, such as distinguishing a noisy
+// pass from one where no signal was received at all.
+type ReceiverStats struct {
+	// Frames successfully received and decoded
+	FramesReceived uint64
+
+	// Sync markers located, regardless of whether the
+	// following frame could be decoded
+	SyncMarkers uint64
+
+	// Bytes discarded while searching for a sync marker
+	BytesSkipped uint64
+
+	// Sum of bit errors tolerated across all located sync markers
+	SyncBitErrors uint64
+
+	// Sync markers located with inverted polarity
+	InvertedSyncMarkers uint64
+
+	// Failures reading from the source, not including io.EOF
+	ReadErrors uint64
+
+	// Frames discarded due to an invalid length field, or for
+	// exceeding FrameSize when a Codec is used
+	FrameLengthErrors uint64
+
+	// Malformed frames reported by the configured Codec
+	CodecErrors uint64
+
+	// Idle frames discarded due to FrameConfig.DropIdleFrames,
+	// which are also included in FramesReceived
+	IdleFramesDropped uint64
+
+	// Unwrap failures for each entry in FrameConfig.Adapters,
+	// with matching indices
+	AdapterErrors []uint64
+}
+
+// Counters describing the activity of a FrameSender.
+type SenderStats struct {
+	// Frames fully written to the destination
+	FramesSent uint64
+
+	// Bytes written to the destination, including sync markers
+	BytesWritten uint64
+
+	// Send operations that failed for any reason
+	SendErrors uint64
+
+	// Send operations rejected with ErrRateLimited, which are
+	// also included in SendErrors
+	RateLimited uint64
+
+	// Idle frames generated by a ContinuousSender, which are
+	// also included in FramesSent
+	IdleFramesSent uint64
+}
+
+// Returns a snapshot of the receiver counters. This is safe to
+// call while Receive is running.
+func (r *FrameReceiver) Stats() ReceiverStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	st := r.stats
+	st.AdapterErrors = make([]uint64, len(r.stats.AdapterErrors))
+	copy(st.AdapterErrors, r.stats.AdapterErrors)
+	return st
+}
+
+// Returns a snapshot of the sender counters. This is safe to call
+// concurrently with Send.
+func (s *FrameSender) Stats() SenderStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
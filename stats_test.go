@@ -0,0 +1,133 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package satcom
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/antaris-inc/go-satcom/crc"
+	"github.com/antaris-inc/go-satcom/satlab"
+)
+
+func TestFrameReceiver_Stats(t *testing.T) {
+	crc32Adapter, _ := crc.NewCRC32Adapter(crc.CRC32AdapterConfig{
+		Algorithm: crc.CRC32c,
+	})
+
+	cfg := FrameConfig{
+		FrameSyncMarker:          satlab.SATLAB_ASM,
+		SyncMarkerMaxBitErrors:   2,
+		DetectInvertedSyncMarker: true,
+		FrameSize:                10,
+		Adapters: []Adapter{
+			&satlab.SpaceframeAdapter{
+				SpaceframeConfig: satlab.SpaceframeConfig{
+					Type:            satlab.SPACEFRAME_TYPE_CSP,
+					PayloadDataSize: 4,
+				},
+			},
+			crc32Adapter,
+		},
+	}
+
+	goodFrame := []byte{
+		0x1A, 0xCF, 0xFC, 0x1D, // ASM
+		0x00, 0x02, 0x11, 0x22, 0x00, 0x00, // Spaceframe
+		0xBD, 0x02, 0x11, 0x4E, // CRC checksum
+	}
+
+	badChecksum := []byte{
+		0x1A, 0xCF, 0xFC, 0x1D, // ASM
+		0x00, 0x02, 0x11, 0x22, 0x00, 0x00, // Spaceframe
+		0xBD, 0x02, 0x11, 0x4F, // CRC checksum
+	}
+
+	// valid checksum computed over a Spaceframe header w/ invalid type
+	badHeader := []byte{
+		0x1A, 0xCF, 0xFC, 0x1D, // ASM
+		0x78, 0x02, 0x11, 0x22, 0x00, 0x00, // Spaceframe
+	}
+	badHeader, _ = crc32Adapter.Wrap(badHeader[4:])
+	badHeader = append([]byte{0x1A, 0xCF, 0xFC, 0x1C}, badHeader...)
+
+	invertedFrame := append([]byte{}, goodFrame...)
+	invertBytes(invertedFrame)
+
+	input := bytes.NewBuffer(nil)
+	input.Write([]byte{0x00, 0x01, 0x02}) // garbage
+	input.Write(goodFrame)
+	input.Write(badChecksum)
+	input.Write(badHeader)
+	input.Write([]byte{0x00, 0x01}) // garbage
+	input.Write(invertedFrame)
+
+	fr, err := NewFrameReceiver(cfg, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgC := make(chan []byte, 10)
+	errC := make(chan error, 10)
+	fr.Receive(context.Background(), msgC, errC)
+
+	want := ReceiverStats{
+		FramesReceived:      2,
+		SyncMarkers:         4,
+		BytesSkipped:        5,
+		SyncBitErrors:       1,
+		InvertedSyncMarkers: 1,
+		AdapterErrors:       []uint64{1, 1},
+	}
+	got := fr.Stats()
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected stats: want=%+v got=%+v", want, got)
+	}
+}
+
+func TestFrameSender_Stats(t *testing.T) {
+	cfg := FrameConfig{
+		FrameSyncMarker: []byte{0xFF, 0xFE},
+		FrameSize:       3,
+	}
+
+	buf := bytes.NewBuffer(nil)
+	fs, err := NewFrameSender(cfg, buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := fs.Send([]byte{0x11, 0x22, 0x33}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.Send([]byte{0x11, 0x22, 0x33}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.Send([]byte{0x11, 0x22, 0x33, 0x44}); err == nil {
+		t.Fatalf("expected non-nil error")
+	}
+
+	want := SenderStats{
+		FramesSent:   2,
+		BytesWritten: 10,
+		SendErrors:   1,
+	}
+	got := fs.Stats()
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected stats: want=%+v got=%+v", want, got)
+	}
+}
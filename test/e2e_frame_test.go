@@ -10,32 +10,56 @@ import (
 
 	"github.com/antaris-inc/go-satcom"
 	"github.com/antaris-inc/go-satcom/example"
+	"github.com/antaris-inc/go-satcom/test/srs4emu"
 )
 
-func dialOrSkip(t *testing.T, env string) (net.Conn, error) {
-	uplinkAddr := os.Getenv(env)
-	if uplinkAddr == "" {
-		t.Skip("skipping e2e tests")
+// Determine the uplink and downlink addresses of the modem under test. If
+// these are not provided through the environment, an emulated Satlab SRS4
+// configured to loop back all messages is started.
+func modemAddresses(t *testing.T) (string, string) {
+	uplinkAddr := os.Getenv("TEST_E2E_UPLINK_ADDRESS")
+	downlinkAddr := os.Getenv("TEST_E2E_DOWNLINK_ADDRESS")
+	if uplinkAddr != "" && downlinkAddr != "" {
+		return uplinkAddr, downlinkAddr
 	}
 
+	m, err := srs4emu.Start(srs4emu.Config{
+		UplinkAddress:   "127.0.0.1:0",
+		DownlinkAddress: "127.0.0.1:0",
+	})
+	if err != nil {
+		t.Fatalf("failed starting modem emulator: %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+
+	return m.UplinkAddr().String(), m.DownlinkAddr().String()
+}
+
+func dial(addr string) (net.Conn, error) {
 	var d net.Dialer
-	ctx, _ := context.WithTimeout(context.Background(), 3*time.Second)
-	return d.DialContext(ctx, "tcp", uplinkAddr)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return d.DialContext(ctx, "tcp", addr)
 }
 
 func testE2EFrameLoopback(t *testing.T, cfg satcom.FrameConfig) {
-	uplink, err := dialOrSkip(t, "TEST_E2E_UPLINK_ADDRESS")
+	uplinkAddr, downlinkAddr := modemAddresses(t)
+
+	uplink, err := dial(uplinkAddr)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	defer uplink.Close()
 
-	downlink, err := dialOrSkip(t, "TEST_E2E_DOWNLINK_ADDRESS")
+	downlink, err := dial(downlinkAddr)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	defer downlink.Close()
 
+	// the modem fills the downlink with idle frames
+	cfg.DropIdleFrames = true
+
 	fs, err := satcom.NewFrameSender(cfg, uplink)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -46,28 +70,25 @@ func testE2EFrameLoopback(t *testing.T, cfg satcom.FrameConfig) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	ctx, _ := context.WithTimeout(context.Background(), 3*time.Second)
-	msgC := make(chan []byte)
-	go fr.Receive(ctx, msgC, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
 
 	// write message
 
 	msg := []byte("HELLO WORLD")
-	if err := fs.Send(msg); err != nil {
+	if err := fs.SendContext(ctx, msg); err != nil {
 		t.Fatalf("send operation failed: %v", err)
 	}
 
 	// Then read back the same message and assert the message loops back.
-	// We cannot rely on Receive stopping on its own due to a cancelled context
-	// since the context is not carried through the underlying Read operation.
+	// The context is carried through the underlying Read operation, so
+	// this will not block beyond the deadline.
 
-	var got []byte
-
-	select {
-	case got = <-msgC:
-	case <-ctx.Done():
-		t.Fatalf("failed to read message in time: %v", ctx.Err())
+	frm, err := fr.Next(ctx)
+	if err != nil {
+		t.Fatalf("failed to read message in time: %v", err)
 	}
+	got := frm.Payload
 
 	want := []byte("HELLO WORLD")
 	if !reflect.DeepEqual(want, got) {
@@ -0,0 +1,294 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// Package srs4emu emulates the TCP interface of a Satlab SRS4 transceiver,
+// allowing end-to-end tests to run without radio hardware.
+package srs4emu
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/antaris-inc/go-satcom"
+	"github.com/antaris-inc/go-satcom/example"
+)
+
+// Handles a message received by the emulated spacecraft, returning any
+// messages to be sent in response.
+type SpacecraftFunc func(msg []byte) [][]byte
+
+// Loops every message back to the ground unmodified.
+func Loopback(msg []byte) [][]byte {
+	return [][]byte{msg}
+}
+
+type Config struct {
+	// Addresses on which to accept uplink and downlink connections,
+	// such as "127.0.0.1:0" to choose a port automatically.
+	UplinkAddress   string
+	DownlinkAddress string
+
+	// Time between frames written to the downlink, with idle frames
+	// filling any gaps between messages. Defaults to 100ms.
+	FrameInterval time.Duration
+
+	// Behavior of the spacecraft, defaulting to Loopback.
+	Spacecraft SpacecraftFunc
+
+	// Number of messages buffered for the downlink, beyond which
+	// messages are dropped. Defaults to 64.
+	DownlinkQueueSize int
+}
+
+func (cfg *Config) Err() error {
+	if cfg.UplinkAddress == "" || cfg.DownlinkAddress == "" {
+		return errors.New("UplinkAddress and DownlinkAddress must be provided")
+	}
+	if cfg.FrameInterval < 0 {
+		return errors.New("FrameInterval must not be negative")
+	}
+	if cfg.DownlinkQueueSize < 0 {
+		return errors.New("DownlinkQueueSize must not be negative")
+	}
+	return nil
+}
+
+// Counters describing the activity of a Modem.
+type Stats struct {
+	// Valid frames received on the uplink, excluding idle frames
+	UplinkFrames uint64
+
+	// Uplink frames rejected as invalid
+	UplinkErrors uint64
+
+	// Messages passed to the downlink for transmission
+	DownlinkFrames uint64
+
+	// Messages that could not be framed for the downlink
+	DownlinkErrors uint64
+
+	// Messages dropped as the downlink queue was full
+	DownlinkDropped uint64
+}
+
+// Start an emulated modem, listening for connections on the configured
+// addresses. Frames received on the uplink are validated against
+// example.MakeSatlabSRS4FrameConfig and passed to the spacecraft, with
+// any responses written to the downlink. As with the real transceiver,
+// idle frames are written to the downlink when no messages are waiting.
+func Start(cfg Config) (*Modem, error) {
+	if err := cfg.Err(); err != nil {
+		return nil, err
+	}
+
+	if cfg.FrameInterval == 0 {
+		cfg.FrameInterval = 100 * time.Millisecond
+	}
+	if cfg.Spacecraft == nil {
+		cfg.Spacecraft = Loopback
+	}
+	if cfg.DownlinkQueueSize == 0 {
+		cfg.DownlinkQueueSize = 64
+	}
+
+	frmCfg, err := example.MakeSatlabSRS4FrameConfig()
+	if err != nil {
+		return nil, err
+	}
+	frmCfg.DropIdleFrames = true
+
+	uplinkL, err := net.Listen("tcp", cfg.UplinkAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	downlinkL, err := net.Listen("tcp", cfg.DownlinkAddress)
+	if err != nil {
+		uplinkL.Close()
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := Modem{
+		cfg:       cfg,
+		frmCfg:    frmCfg,
+		uplinkL:   uplinkL,
+		downlinkL: downlinkL,
+		downlinkC: make(chan []byte, cfg.DownlinkQueueSize),
+		ctx:       ctx,
+		cancel:    cancel,
+		conns:     map[net.Conn]struct{}{},
+	}
+
+	m.wg.Add(2)
+	go m.accept(uplinkL, m.serveUplink)
+	go m.accept(downlinkL, m.serveDownlink)
+
+	return &m, nil
+}
+
+// Emulated Satlab SRS4 transceiver, see Start.
+type Modem struct {
+	cfg    Config
+	frmCfg satcom.FrameConfig
+
+	uplinkL   net.Listener
+	downlinkL net.Listener
+
+	// messages waiting to be written to the downlink
+	downlinkC chan []byte
+
+	ctx    context.Context
+	cancel func()
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	stats Stats
+}
+
+func (m *Modem) UplinkAddr() net.Addr {
+	return m.uplinkL.Addr()
+}
+
+func (m *Modem) DownlinkAddr() net.Addr {
+	return m.downlinkL.Addr()
+}
+
+// Returns a snapshot of the modem counters.
+func (m *Modem) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+// Stop accepting connections and close all existing connections.
+func (m *Modem) Close() error {
+	m.cancel()
+	m.uplinkL.Close()
+	m.downlinkL.Close()
+
+	m.mu.Lock()
+	for conn := range m.conns {
+		conn.Close()
+	}
+	m.mu.Unlock()
+
+	m.wg.Wait()
+	return nil
+}
+
+func (m *Modem) count(c *uint64) {
+	m.mu.Lock()
+	*c += 1
+	m.mu.Unlock()
+}
+
+func (m *Modem) accept(l net.Listener, serve func(net.Conn)) {
+	defer m.wg.Done()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+
+		m.mu.Lock()
+		m.conns[conn] = struct{}{}
+		m.mu.Unlock()
+
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			defer func() {
+				m.mu.Lock()
+				delete(m.conns, conn)
+				m.mu.Unlock()
+				conn.Close()
+			}()
+			serve(conn)
+		}()
+	}
+}
+
+// Receive frames from the ground, passing valid messages to the spacecraft.
+func (m *Modem) serveUplink(conn net.Conn) {
+	fr, err := satcom.NewFrameReceiver(m.frmCfg, conn)
+	if err != nil {
+		return
+	}
+
+	for {
+		frm, err := fr.Next(m.ctx)
+		if err != nil {
+			// the real transceiver silently discards invalid frames
+			if errors.Is(err, satcom.ErrDecodeFailure) {
+				m.count(&m.stats.UplinkErrors)
+				continue
+			}
+			return
+		}
+
+		m.count(&m.stats.UplinkFrames)
+
+		for _, resp := range m.cfg.Spacecraft(frm.Payload) {
+			select {
+			case m.downlinkC <- resp:
+			default:
+				m.count(&m.stats.DownlinkDropped)
+			}
+		}
+	}
+}
+
+// Write queued messages to the ground, along with idle frames.
+func (m *Modem) serveDownlink(conn net.Conn) {
+	cs, err := satcom.NewContinuousSender(satcom.ContinuousSenderConfig{
+		FrameConfig:   m.frmCfg,
+		FrameInterval: m.cfg.FrameInterval,
+		IdleFrameMode: satcom.IDLE_FRAME_MODE_EMPTY,
+	}, conn)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	defer cancel()
+
+	// stop queueing messages once the connection fails
+	go func() {
+		cs.Run(ctx)
+		cancel()
+	}()
+
+	for {
+		select {
+		case msg := <-m.downlinkC:
+			if err := cs.Send(ctx, msg); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				// a single bad message must not tear down the link
+				m.count(&m.stats.DownlinkErrors)
+				continue
+			}
+			m.count(&m.stats.DownlinkFrames)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,196 @@
+//   Copyright 2023 Antaris, Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package srs4emu
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/antaris-inc/go-satcom"
+	"github.com/antaris-inc/go-satcom/example"
+)
+
+func startModem(t *testing.T, cfg Config) (*Modem, *satcom.Link) {
+	cfg.UplinkAddress = "127.0.0.1:0"
+	cfg.DownlinkAddress = "127.0.0.1:0"
+
+	m, err := Start(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+
+	uplink, err := net.Dial("tcp", m.UplinkAddr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	downlink, err := net.Dial("tcp", m.DownlinkAddr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	frmCfg, err := example.MakeSatlabSRS4FrameConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	frmCfg.DropIdleFrames = true
+
+	l, err := satcom.NewSplitLink(satcom.LinkConfig{Transmit: frmCfg, Receive: frmCfg}, uplink, downlink)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	return m, l
+}
+
+func TestConfig_Err(t *testing.T) {
+	tests := []struct {
+		cfg     Config
+		wantErr bool
+	}{
+		{Config{UplinkAddress: ":0", DownlinkAddress: ":0"}, false},
+		{Config{UplinkAddress: ":0"}, true},
+		{Config{UplinkAddress: ":0", DownlinkAddress: ":0", FrameInterval: -1}, true},
+		{Config{UplinkAddress: ":0", DownlinkAddress: ":0", DownlinkQueueSize: -1}, true},
+	}
+
+	for i, tt := range tests {
+		err := tt.cfg.Err()
+		if tt.wantErr != (err != nil) {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+		}
+	}
+}
+
+func TestModem_Loopback(t *testing.T) {
+	m, l := startModem(t, Config{FrameInterval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := l.Send(ctx, []byte("HELLO")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := l.Recv(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []byte("HELLO"); !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected result: want=% x got=% x", want, got)
+	}
+
+	// idle frames fill the downlink while there is nothing to send
+	idleCtx, idleCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer idleCancel()
+	if _, err := l.Recv(idleCtx); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if st := l.Stats().Receive; st.IdleFramesDropped == 0 {
+		t.Errorf("expected idle frames: %+v", st)
+	}
+
+	if st := m.Stats(); st.UplinkFrames != 1 || st.DownlinkFrames != 1 {
+		t.Errorf("unexpected stats: %+v", st)
+	}
+}
+
+func TestModem_Spacecraft(t *testing.T) {
+	spacecraft := func(msg []byte) [][]byte {
+		return [][]byte{
+			append([]byte("ACK "), msg...),
+			bytes.ToLower(msg),
+		}
+	}
+	_, l := startModem(t, Config{FrameInterval: 10 * time.Millisecond, Spacecraft: spacecraft})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := l.Send(ctx, []byte("PING")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"ACK PING", "ping"} {
+		got, err := l.Recv(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(got) != want {
+			t.Errorf("unexpected result: want=%q got=%q", want, got)
+		}
+	}
+}
+
+// Confirms a response too large to frame is dropped without closing
+// the downlink.
+func TestModem_OversizedResponse(t *testing.T) {
+	spacecraft := func(msg []byte) [][]byte {
+		return [][]byte{make([]byte, 1024), msg}
+	}
+	m, l := startModem(t, Config{FrameInterval: 10 * time.Millisecond, Spacecraft: spacecraft})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := l.Send(ctx, []byte("PING")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := l.Recv(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "PING"; string(got) != want {
+		t.Errorf("unexpected result: want=%q got=%q", want, got)
+	}
+
+	if st := m.Stats(); st.DownlinkErrors != 1 || st.DownlinkFrames != 1 {
+		t.Errorf("unexpected stats: %+v", st)
+	}
+}
+
+func TestModem_InvalidFrame(t *testing.T) {
+	m, _ := startModem(t, Config{})
+
+	conn, err := net.Dial("tcp", m.UplinkAddr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+
+	// a Spaceframe with a corrupted checksum
+	frm := append([]byte{0x1A, 0xCF, 0xFC, 0x1D}, make([]byte, 223)...)
+	if _, err := conn.Write(frm); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for m.Stats().UplinkErrors == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("invalid frame not rejected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if st := m.Stats(); st.UplinkFrames != 0 {
+		t.Errorf("unexpected stats: %+v", st)
+	}
+}